@@ -0,0 +1,216 @@
+package bucketeer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+Backup writes a consistent copy of the entire database to the provided writer, in a View transaction so other readers and writers are not blocked. It returns the number of bytes written.
+*/
+func Backup(db *bolt.DB, w io.Writer) (n int64, err error) {
+	txf := func(tx *bolt.Tx) (err error) {
+		n, err = tx.WriteTo(w)
+		return
+	}
+	err = db.View(txf)
+	return
+}
+
+/*
+BackupToFile writes a consistent copy of the entire database to the named file. The copy is written to a temporary file in the same directory and renamed once it has been synced, so the named file is never left incomplete.
+*/
+func BackupToFile(db *bolt.DB, filename string) (n int64, err error) {
+	writeFunc := func(tmpName string) (err error) {
+		var f *os.File
+		if f, err = os.OpenFile(tmpName, os.O_WRONLY, 0); err != nil {
+			return
+		}
+		if n, err = Backup(db, f); err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return
+	}
+	err = replaceFile(filename, writeFunc)
+	return
+}
+
+/*
+replaceFile calls the provided function with the name of a new, empty temporary file in the same directory as the named file, then renames the temporary file to the named file, replacing any existing file. If the function fails, the temporary file is removed and the named file is left as it was.
+*/
+func replaceFile(filename string, writeFunc func(tmpName string) error) (err error) {
+	var f *os.File
+	if f, err = os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp"); err != nil {
+		return
+	}
+	tmpName := f.Name()
+	defer func() {
+		if err != nil {
+			os.Remove(tmpName)
+		}
+	}()
+	if err = f.Close(); err != nil {
+		return
+	}
+	if err = writeFunc(tmpName); err != nil {
+		return
+	}
+	err = os.Rename(tmpName, filename)
+	return
+}
+
+/*
+VerifyFile opens a bolt file read-only, checks the consistency of its pages, and walks every bucket in it. It returns the number of buckets found.
+*/
+func VerifyFile(filename string) (buckets int, err error) {
+	var db *bolt.DB
+	if db, err = bolt.Open(filename, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true}); err != nil {
+		return
+	}
+	defer db.Close()
+	txf := func(tx *bolt.Tx) (err error) {
+		for checkErr := range tx.Check() {
+			if err == nil {
+				err = checkErr
+			}
+		}
+		if err != nil {
+			return
+		}
+		walkFunc := func(path Path, stats bolt.BucketStats, depth int) error {
+			buckets++
+			return nil
+		}
+		err = WalkTx(tx, nil, -1, walkFunc)
+		return
+	}
+	err = db.View(txf)
+	return
+}
+
+/*
+SnapshotReport describes a snapshot taken by a Snapshotter.
+*/
+type SnapshotReport struct {
+	Filename string
+	Time     time.Time
+	Size     int64
+	Duration time.Duration
+	Buckets  int
+}
+
+/*
+Snapshotter writes timestamped, verified copies of a database to a directory, and removes all but the most recent ones.
+*/
+type Snapshotter struct {
+	db     *bolt.DB
+	dir    string
+	prefix string
+	keep   int
+	loop   periodic
+
+	// OnSnapshot, if set, is called after each snapshot taken by the background goroutine started with Start. It must be set before Start is called.
+	OnSnapshot func(report SnapshotReport, err error)
+}
+
+const snapshotSuffix = ".db"
+
+/*
+NewSnapshotter creates a Snapshotter which writes snapshots of the database to the provided directory every interval, keeping the most recent keep snapshots. If keep is zero or less, no snapshots are removed. Snapshot files are named with the database file's base name and a UTC timestamp, so they sort in the order they were taken. If the interval is not positive, this function will panic.
+*/
+func NewSnapshotter(db *bolt.DB, dir string, interval time.Duration, keep int) (s *Snapshotter) {
+	name := filepath.Base(db.Path())
+	s = &Snapshotter{
+		db:     db,
+		dir:    dir,
+		prefix: strings.TrimSuffix(name, filepath.Ext(name)) + "-",
+		keep:   keep,
+		loop:   newPeriodic(interval),
+	}
+	return
+}
+
+/*
+Snapshot writes and verifies a snapshot immediately, then removes old snapshots beyond the number to keep. A snapshot which fails verification is removed, and old snapshots are left in place.
+*/
+func (s *Snapshotter) Snapshot() (report SnapshotReport, err error) {
+	start := time.Now()
+	report.Time = start.UTC()
+	report.Filename = filepath.Join(s.dir, s.prefix+report.Time.Format("20060102T150405.000000000Z")+snapshotSuffix)
+	if report.Size, err = BackupToFile(s.db, report.Filename); err != nil {
+		return
+	}
+	if report.Buckets, err = VerifyFile(report.Filename); err != nil {
+		os.Remove(report.Filename)
+		err = fmt.Errorf("Snapshot %s failed verification: %s", report.Filename, err.Error())
+		return
+	}
+	report.Duration = time.Since(start)
+	err = s.prune()
+	return
+}
+
+/*
+Snapshots returns the filenames of the existing snapshots in the directory, oldest first.
+*/
+func (s *Snapshotter) Snapshots() (filenames []string, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(s.dir); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, s.prefix) && strings.HasSuffix(name, snapshotSuffix) {
+			filenames = append(filenames, filepath.Join(s.dir, name))
+		}
+	}
+	sort.Strings(filenames)
+	return
+}
+
+func (s *Snapshotter) prune() (err error) {
+	if s.keep <= 0 {
+		return
+	}
+	var filenames []string
+	if filenames, err = s.Snapshots(); err != nil {
+		return
+	}
+	for len(filenames) > s.keep {
+		if err = os.Remove(filenames[0]); err != nil {
+			return
+		}
+		filenames = filenames[1:]
+	}
+	return
+}
+
+/*
+Start starts a goroutine which takes a snapshot every interval until Stop is called. Calling Start on a running Snapshotter has no effect.
+*/
+func (s *Snapshotter) Start() {
+	tickFunc := func(stop <-chan struct{}) {
+		report, err := s.Snapshot()
+		if s.OnSnapshot != nil {
+			s.OnSnapshot(report, err)
+		}
+	}
+	s.loop.start(tickFunc)
+}
+
+/*
+Stop stops the goroutine started by Start and waits for any snapshot in progress to finish.
+*/
+func (s *Snapshotter) Stop() {
+	s.loop.halt()
+}
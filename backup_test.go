@@ -0,0 +1,106 @@
+package bucketeer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestBackup(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	New(db, "a", "b").EnsurePathBuckets()
+	New(db, "a").ForStringKey("k").PutStringValue("v")
+
+	filename := tempfile()
+	defer os.Remove(filename)
+	var n int64
+	if n, err = BackupToFile(db, filename); err != nil {
+		t.Fatal(err.Error())
+	}
+	if info, _ := os.Stat(filename); info == nil || info.Size() != n {
+		t.Fatalf("Expected a %d byte file, got %v\n", n, info)
+	}
+	var buckets int
+	if buckets, err = VerifyFile(filename); err != nil {
+		t.Fatal(err.Error())
+	}
+	if buckets != 2 {
+		t.Fatalf("Expected 2 buckets, got %d\n", buckets)
+	}
+}
+
+func TestSnapshotter(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+	New(db, "a").EnsurePathBuckets()
+
+	dir, err := os.MkdirTemp("", "bolt-snapshots-")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewSnapshotter(db, dir, 10*time.Millisecond, 2)
+	var last SnapshotReport
+	for i := 0; i < 3; i++ {
+		if last, err = s.Snapshot(); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if last.Buckets != 1 || last.Size == 0 {
+		t.Fatalf("Expected a report for 1 bucket, got %+v\n", last)
+	}
+	var filenames []string
+	if filenames, err = s.Snapshots(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(filenames) != 2 || filenames[1] != last.Filename {
+		t.Fatalf("Expected 2 snapshots ending with %s, got %v\n", last.Filename, filenames)
+	}
+
+	reports := make(chan error, 10)
+	s.OnSnapshot = func(report SnapshotReport, err error) {
+		select {
+		case reports <- err:
+		default:
+		}
+	}
+	s.Start()
+	select {
+	case err = <-reports:
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a snapshot from the background goroutine")
+	}
+	s.Stop()
+
+	var matches []string
+	if matches, err = filepath.Glob(filepath.Join(dir, "*")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 files after Stop, got %v\n", matches)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic for a zero interval")
+		}
+	}()
+	NewSnapshotter(db, dir, 0, 2)
+}
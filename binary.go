@@ -11,23 +11,15 @@ import (
 /*
 PutBinaryValue marshals the provided object into its binary form and sets it as the value for the key.
 */
-func PutBinaryValue(b *bolt.Bucket, key []byte, valueObj encoding.BinaryMarshaler) (err error) {
-	var value []byte
-	if value, err = valueObj.MarshalBinary(); err != nil {
-		return
-	}
-	err = b.Put(key, value)
-	return
+func PutBinaryValue(b *bolt.Bucket, key []byte, valueObj encoding.BinaryMarshaler) error {
+	return PutValue(b, key, valueObj, BinaryCodec)
 }
 
 /*
 UnmarshalBinaryValue gets the key's value and unmarshals it into the provided object.
 */
-func UnmarshalBinaryValue(b *bolt.Bucket, key []byte, valueObj encoding.BinaryUnmarshaler) (err error) {
-	if value := b.Get(key); value != nil {
-		err = valueObj.UnmarshalBinary(value)
-	}
-	return
+func UnmarshalBinaryValue(b *bolt.Bucket, key []byte, valueObj encoding.BinaryUnmarshaler) error {
+	return UnmarshalValue(b, key, valueObj, BinaryCodec)
 }
 
 /*
@@ -50,14 +42,18 @@ func GetInt64Value(b *bolt.Bucket, key []byte) (value int64, err error) {
 }
 
 /*
-IncrementInt64Value increments the key's value by the provided value, and returns the updated value.
+IncrementInt64Value increments the key's value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero. If the result would overflow, ErrOverflow is returned and the value is not changed.
 */
 func IncrementInt64Value(b *bolt.Bucket, key []byte, value int64) (newValue int64, err error) {
 	var oldValue int64
-	if oldValue, err = GetInt64Value(b, key); err != nil {
+	if v := b.Get(key); v != nil {
+		if oldValue, err = GetInt64Value(b, key); err != nil {
+			return
+		}
+	}
+	if newValue, err = addInt64(oldValue, value); err != nil {
 		return
 	}
-	newValue = oldValue + value
 	err = PutInt64Value(b, key, newValue)
 	return
 }
@@ -76,24 +72,22 @@ func PutUint64Value(b *bolt.Bucket, key []byte, value uint64) (err error) {
 GetUint64Value gets the key's value and converts its bytes into a uint64 value. The value must be 8 bytes with the bits in big-endian ordering.
 */
 func GetUint64Value(b *bolt.Bucket, key []byte) (value uint64, err error) {
-	v := b.Get(key)
-	if len(v) != 8 {
-		err = errors.New("Value is not 8 bytes")
-		return
-	}
-	value = binary.BigEndian.Uint64(v)
-	return
+	return decodeUint64Value(b.Get(key))
 }
 
 /*
-IncrementUint64Value increments the key's value by the provided value, and returns the updated value.
+IncrementUint64Value increments the key's value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero. If the result would overflow, ErrOverflow is returned and the value is not changed.
 */
 func IncrementUint64Value(b *bolt.Bucket, key []byte, value uint64) (newValue uint64, err error) {
 	var oldValue uint64
-	if oldValue, err = GetUint64Value(b, key); err != nil {
+	if v := b.Get(key); v != nil {
+		if oldValue, err = GetUint64Value(b, key); err != nil {
+			return
+		}
+	}
+	if newValue, err = addUint64(oldValue, value); err != nil {
 		return
 	}
-	newValue = oldValue + value
 	err = PutUint64Value(b, key, newValue)
 	return
 }
@@ -112,14 +106,7 @@ func PutVarintValue(b *bolt.Bucket, key []byte, value int64) (err error) {
 GetVarintValue gets the key's value and decodes it into an int64 value using variable-length decoding.
 */
 func GetVarintValue(b *bolt.Bucket, key []byte) (value int64, err error) {
-	var v []byte
-	if v = b.Get(key); len(v) != 0 {
-		var chk int
-		if value, chk = binary.Varint(v); chk <= 0 {
-			err = errors.New("Value is not an int64")
-		}
-	}
-	return
+	return decodeVarintValue(b.Get(key))
 }
 
 /*
@@ -136,8 +123,75 @@ func PutUvarintValue(b *bolt.Bucket, key []byte, value uint64) (err error) {
 GetUvarintValue gets the key's value and decodes it into a uint64 value using variable-length decoding.
 */
 func GetUvarintValue(b *bolt.Bucket, key []byte) (value uint64, err error) {
-	var v []byte
-	if v = b.Get(key); len(v) != 0 {
+	return decodeUvarintValue(b.Get(key))
+}
+
+/*
+IncrementVarintValue increments the key's variable-length encoded value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero. If the result would overflow, ErrOverflow is returned and the value is not changed.
+*/
+func IncrementVarintValue(b *bolt.Bucket, key []byte, value int64) (newValue int64, err error) {
+	var oldValue int64
+	if oldValue, err = GetVarintValue(b, key); err != nil {
+		return
+	}
+	if newValue, err = addInt64(oldValue, value); err != nil {
+		return
+	}
+	err = PutVarintValue(b, key, newValue)
+	return
+}
+
+/*
+IncrementUvarintValue increments the key's variable-length encoded value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero. If the result would overflow, ErrOverflow is returned and the value is not changed.
+*/
+func IncrementUvarintValue(b *bolt.Bucket, key []byte, value uint64) (newValue uint64, err error) {
+	var oldValue uint64
+	if oldValue, err = GetUvarintValue(b, key); err != nil {
+		return
+	}
+	if newValue, err = addUint64(oldValue, value); err != nil {
+		return
+	}
+	err = PutUvarintValue(b, key, newValue)
+	return
+}
+
+func addInt64(a, b int64) (sum int64, err error) {
+	sum = a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		err = ErrOverflow
+	}
+	return
+}
+
+func addUint64(a, b uint64) (sum uint64, err error) {
+	if sum = a + b; sum < a {
+		err = ErrOverflow
+	}
+	return
+}
+
+func decodeUint64Value(v []byte) (value uint64, err error) {
+	if len(v) != 8 {
+		err = errors.New("Value is not 8 bytes")
+		return
+	}
+	value = binary.BigEndian.Uint64(v)
+	return
+}
+
+func decodeVarintValue(v []byte) (value int64, err error) {
+	if len(v) != 0 {
+		var chk int
+		if value, chk = binary.Varint(v); chk <= 0 {
+			err = errors.New("Value is not an int64")
+		}
+	}
+	return
+}
+
+func decodeUvarintValue(v []byte) (value uint64, err error) {
+	if len(v) != 0 {
 		var chk int
 		if value, chk = binary.Uvarint(v); chk <= 0 {
 			err = errors.New("Value is not a uint64")
@@ -2,7 +2,6 @@ package bucketeer
 
 import (
 	"encoding"
-	"fmt"
 
 	"github.com/boltdb/bolt"
 )
@@ -11,8 +10,12 @@ import (
 Bucketeer encapsulates the components needed to resolve a bucket in BoltDB and provides convenience methods for initializing Keyfarers for various key types.
 */
 type Bucketeer struct {
-	db   *bolt.DB
-	path Path
+	db     *bolt.DB
+	path   Path
+	codec  Codec
+	strict bool
+	batch  bool
+	tx     *bolt.Tx
 }
 
 /*
@@ -37,25 +40,72 @@ func ForPath(db *bolt.DB, path Path) (bb *Bucketeer) {
 	return
 }
 
+/*
+WithCodec creates a copy of this Bucketeer which uses the provided Codec as its default for Keyfarer values.
+*/
+func (bb *Bucketeer) WithCodec(codec Codec) *Bucketeer {
+	nb := *bb
+	nb.codec = codec
+	return &nb
+}
+
+/*
+WithStrict creates a copy of this Bucketeer with strict mode set as provided. In strict mode, transactions return a BucketNotFoundError if the bucket path does not exist, and Keyfarer reads return a KeyNotFoundError if the key does not exist.
+*/
+func (bb *Bucketeer) WithStrict(strict bool) *Bucketeer {
+	nb := *bb
+	nb.strict = strict
+	return &nb
+}
+
+/*
+WithBatch creates a copy of this Bucketeer with batch mode set as provided. In batch mode, Update transactions are made with bolt's DB.Batch, which coalesces concurrent writes from multiple goroutines into fewer commits.
+
+Because bolt may re-run a batched function on its own after another function in the batch fails, update functions must not have side effects outside of the transaction. An error returned by an update function is only returned to its own caller, never to the other callers in the batch. A single caller does not gain anything from batch mode; its writes are delayed by up to the DB's MaxBatchDelay.
+*/
+func (bb *Bucketeer) WithBatch(batch bool) *Bucketeer {
+	nb := *bb
+	nb.batch = batch
+	return &nb
+}
+
+/*
+Codec returns the default Codec for Keyfarer values. If none was set, JsonCodec is used.
+*/
+func (bb *Bucketeer) Codec() Codec {
+	if bb.codec == nil {
+		return JsonCodec
+	}
+	return bb.codec
+}
+
 /*
 EnsurePathBuckets creates any buckets along the provided path if they do not exist.
 */
 func (bb *Bucketeer) EnsurePathBuckets() error {
-	return EnsurePathBuckets(bb.db, bb.path)
+	txf := func(tx *bolt.Tx) error {
+		return ensurePathBuckets(tx, bb.path)
+	}
+	return bb.updateTx(txf)
 }
 
 /*
 EnsureNestedBucket creates a nested bucket if it does not exist. The bucket's full parent path must exist.
 */
 func (bb *Bucketeer) EnsureNestedBucket(bucket string) error {
-	return EnsureNestedBucket(bb.db, bb.path, bucket)
+	txf := func(tx *bolt.Tx) error {
+		return ensureNestedBucket(tx, bb.path, bucket)
+	}
+	return bb.updateTx(txf)
 }
 
 /*
-InNestedBucket creates a new Bucketeer for a nested bucket with the provided name.
+InNestedBucket creates a new Bucketeer for a nested bucket with the provided name. The new Bucketeer has the same default Codec and strict mode.
 */
 func (bb *Bucketeer) InNestedBucket(bucket string) *Bucketeer {
-	return ForPath(bb.db, bb.path.Nest(bucket))
+	nb := *bb
+	nb.path = bb.path.Nest(bucket)
+	return &nb
 }
 
 /*
@@ -69,11 +119,11 @@ func (bb *Bucketeer) DeleteNestedBucket(bucket string) error {
 }
 
 /*
-GetBucketStats retrieves the BucketStats for the current bucket.
+GetBucketStats retrieves the BucketStats for the current bucket, excluding the nested buckets named MetaBucketName.
 */
 func (bb *Bucketeer) GetBucketStats() (stats bolt.BucketStats, err error) {
 	bf := func(b *bolt.Bucket) (err error) {
-		stats = b.Stats()
+		stats = bucketStats(b)
 		return
 	}
 	err = bb.View(bf)
@@ -84,14 +134,37 @@ func (bb *Bucketeer) GetBucketStats() (stats bolt.BucketStats, err error) {
 View executes the provided function in a View transaction.
 */
 func (bb *Bucketeer) View(viewFunc func(b *bolt.Bucket) error) error {
-	return ViewInBucket(bb.db, bb.path, viewFunc)
+	return bb.viewTx(bucketTxFunc(bb.path, bb.strict, viewFunc))
 }
 
 /*
 Update executes the provided function in an Update transaction.
 */
 func (bb *Bucketeer) Update(updateFunc func(b *bolt.Bucket) error) error {
-	return UpdateInBucket(bb.db, bb.path, updateFunc)
+	return bb.updateTx(bucketTxFunc(bb.path, bb.strict, updateFunc))
+}
+
+/*
+viewTx executes the provided function in a View transaction, or in the bound transaction if this Bucketeer belongs to a BucketTx.
+*/
+func (bb *Bucketeer) viewTx(txFunc func(tx *bolt.Tx) error) error {
+	if bb.tx != nil {
+		return txFunc(bb.tx)
+	}
+	return bb.db.View(txFunc)
+}
+
+/*
+updateTx executes the provided function in an Update transaction, or in the bound transaction if this Bucketeer belongs to a BucketTx. In batch mode, the transaction is made with DB.Batch.
+*/
+func (bb *Bucketeer) updateTx(txFunc func(tx *bolt.Tx) error) error {
+	if bb.tx != nil {
+		return txFunc(bb.tx)
+	}
+	if bb.batch {
+		return bb.db.Batch(txFunc)
+	}
+	return bb.db.Update(txFunc)
 }
 
 /*
@@ -110,10 +183,50 @@ func (bb *Bucketeer) UpdateWithSequence(updateFunc func(b *bolt.Bucket, sequence
 }
 
 /*
-ForByteKey creates a new Keyfarer for the provided key.
+ForKey creates a new Keyfarer for the provided key. If the key implements KeyEncoder and encoding fails, the error is returned by the Keyfarer's operations.
 */
-func (bb *Bucketeer) ForKey(key Key) *Keyfarer {
-	return NewKeyfarer(bb, key.KeyBytes())
+func (bb *Bucketeer) ForKey(key Key) (kf *Keyfarer) {
+	kb, err := EncodeKey(key)
+	kf = NewKeyfarer(bb, kb)
+	kf.err = err
+	return
+}
+
+/*
+ForKeyEncoder creates a new Keyfarer for the provided key. If encoding fails, the error is returned by the Keyfarer's operations.
+*/
+func (bb *Bucketeer) ForKeyEncoder(key KeyEncoder) (kf *Keyfarer) {
+	kb, err := key.EncodeKey()
+	kf = NewKeyfarer(bb, kb)
+	kf.err = err
+	return
+}
+
+/*
+KeyDelta is a key and the amount to increment its value by, for IncrementMany.
+*/
+type KeyDelta struct {
+	Key   Key
+	Delta int64
+}
+
+/*
+IncrementMany increments the values of several keys in a single Update transaction, and returns the updated values in the same order as the deltas. Values are stored in the same big-endian form as IncrementInt64Value, and keys which do not exist are treated as zero. A key which appears more than once is incremented by each of its deltas in turn. If any increment fails, none are applied.
+*/
+func (bb *Bucketeer) IncrementMany(deltas []KeyDelta) (newValues []int64, err error) {
+	txnFunc := func(t *BucketTx) (err error) {
+		newValues = make([]int64, len(deltas))
+		for i, d := range deltas {
+			if newValues[i], err = t.ForKey(d.Key).IncrementInt64Value(d.Delta); err != nil {
+				return
+			}
+		}
+		return
+	}
+	if err = bb.Txn(txnFunc); err != nil {
+		newValues = nil
+	}
+	return
 }
 
 /*
@@ -145,65 +258,87 @@ func (bb *Bucketeer) ForInt64Key(key int64) *Keyfarer {
 }
 
 /*
-ForTextKey creates a new Keyfarer for the textual form of the provided object. If there is an error marshaling the object to text, this function will panic.
+ForTextKey creates a new Keyfarer for the textual form of the provided object. If there is an error marshaling the object to text, the error is returned by the Keyfarer's operations.
 */
 func (bb *Bucketeer) ForTextKey(keyObj encoding.TextMarshaler) *Keyfarer {
 	return bb.ForKey(NewTextKey(keyObj))
 }
 
 /*
-ForBinaryKey creates a new Keyfarer for the binary form of the provided object. If there is an error marshaling the object to binary, this function will panic.
+ForBinaryKey creates a new Keyfarer for the binary form of the provided object. If there is an error marshaling the object to binary, the error is returned by the Keyfarer's operations.
 */
 func (bb *Bucketeer) ForBinaryKey(keyObj encoding.BinaryMarshaler) *Keyfarer {
 	return bb.ForKey(NewBinaryKey(keyObj))
 }
 
 /*
-ForJsonKey creates a new Keyfarer for the JSON form of the provided object. If there is an error marshaling the object to JSON, this function will panic.
+ForJsonKey creates a new Keyfarer for the JSON form of the provided object. If there is an error marshaling the object to JSON, the error is returned by the Keyfarer's operations.
 */
 func (bb *Bucketeer) ForJsonKey(keyObj interface{}) *Keyfarer {
 	return bb.ForKey(NewJsonKey(keyObj))
 }
 
+/*
+ForTupleKey creates a new Keyfarer for a TupleKey made of the provided components. If a component has an unsupported type, the error is returned by the Keyfarer's operations.
+*/
+func (bb *Bucketeer) ForTupleKey(parts ...interface{}) *Keyfarer {
+	return bb.ForKey(NewTupleKey(parts...))
+}
+
 /*
 EnsurePathBuckets creates any buckets along the provided path if they do not exist.
 */
-func EnsurePathBuckets(db *bolt.DB, path Path) (err error) {
+func EnsurePathBuckets(db *bolt.DB, path Path) error {
 	if len(path) == 0 {
 		panic("Path must have at least one element")
 	}
-	txf := func(tx *bolt.Tx) (err error) {
-		var b *bolt.Bucket
-		b, err = tx.CreateBucketIfNotExists(path[0])
-		if err != nil || b == nil || len(path) == 1 {
-			return
-		}
-		for _, bucket := range path[1:] {
-			b, err = b.CreateBucketIfNotExists(bucket)
-			if err != nil || b == nil {
-				return
-			}
-		}
-		return
+	txf := func(tx *bolt.Tx) error {
+		return ensurePathBuckets(tx, path)
 	}
-	err = db.Update(txf)
-	return
+	return db.Update(txf)
 }
 
 /*
 EnsureNestedBucket creates a nested bucket if it does not exist. The bucket's full parent path must exist.
 */
-func EnsureNestedBucket(db *bolt.DB, path Path, bucket string) (err error) {
-	txf := func(tx *bolt.Tx) (err error) {
-		var b *bolt.Bucket
-		if b = GetBucket(tx, path); b == nil {
-			err = fmt.Errorf("Did not find one or more path buckets: %s", path.String())
+func EnsureNestedBucket(db *bolt.DB, path Path, bucket string) error {
+	txf := func(tx *bolt.Tx) error {
+		return ensureNestedBucket(tx, path, bucket)
+	}
+	return db.Update(txf)
+}
+
+func ensurePathBuckets(tx *bolt.Tx, path Path) (err error) {
+	_, err = ensureBucket(tx, path)
+	return
+}
+
+/*
+ensureBucket creates any buckets along the provided path if they do not exist, and returns the last (innermost) bucket.
+*/
+func ensureBucket(tx *bolt.Tx, path Path) (b *bolt.Bucket, err error) {
+	if len(path) == 0 {
+		panic("Path must have at least one element")
+	}
+	b, err = tx.CreateBucketIfNotExists(path[0])
+	if err != nil || b == nil || len(path) == 1 {
+		return
+	}
+	for _, bucket := range path[1:] {
+		b, err = b.CreateBucketIfNotExists(bucket)
+		if err != nil || b == nil {
 			return
 		}
-		_, err = b.CreateBucketIfNotExists([]byte(bucket))
+	}
+	return
+}
+
+func ensureNestedBucket(tx *bolt.Tx, path Path, bucket string) (err error) {
+	var b *bolt.Bucket
+	if b, err = RequireBucket(tx, path); err != nil {
 		return
 	}
-	err = db.Update(txf)
+	_, err = b.CreateBucketIfNotExists([]byte(bucket))
 	return
 }
 
@@ -227,29 +362,46 @@ func GetBucket(tx *bolt.Tx, path Path) (b *bolt.Bucket) {
 }
 
 /*
-ViewInBucket executes the provided function in a View transaction.
+RequireBucket retrieves the last (innermost) bucket of the provided path for use within a transaction. If any bucket along the path does not exist, a BucketNotFoundError is returned.
 */
-func ViewInBucket(db *bolt.DB, path Path, viewFunc func(b *bolt.Bucket) error) (err error) {
-	txf := func(tx *bolt.Tx) (err error) {
-		if b := GetBucket(tx, path); b != nil {
-			err = viewFunc(b)
-		}
-		return
+func RequireBucket(tx *bolt.Tx, path Path) (b *bolt.Bucket, err error) {
+	if b = GetBucket(tx, path); b == nil {
+		err = &BucketNotFoundError{Path: path}
 	}
-	err = db.View(txf)
 	return
 }
 
 /*
-UpdateInBucket executes the provided function in an Update transaction.
+ViewInBucket executes the provided function in a View transaction. If the bucket does not exist, the function is not called and no error is returned.
 */
-func UpdateInBucket(db *bolt.DB, path Path, updateFunc func(b *bolt.Bucket) error) (err error) {
-	txf := func(tx *bolt.Tx) (err error) {
-		if b := GetBucket(tx, path); b != nil {
-			err = updateFunc(b)
+func ViewInBucket(db *bolt.DB, path Path, viewFunc func(b *bolt.Bucket) error) error {
+	return db.View(bucketTxFunc(path, false, viewFunc))
+}
+
+/*
+UpdateInBucket executes the provided function in an Update transaction. If the bucket does not exist, the function is not called and no error is returned.
+*/
+func UpdateInBucket(db *bolt.DB, path Path, updateFunc func(b *bolt.Bucket) error) error {
+	return db.Update(bucketTxFunc(path, false, updateFunc))
+}
+
+/*
+UpdateInBucketBatch executes the provided function in a batched Update transaction via DB.Batch. The function may be called more than once, so it must not have side effects outside of the transaction. If the bucket does not exist, the function is not called and no error is returned.
+*/
+func UpdateInBucketBatch(db *bolt.DB, path Path, updateFunc func(b *bolt.Bucket) error) error {
+	return db.Batch(bucketTxFunc(path, false, updateFunc))
+}
+
+func bucketTxFunc(path Path, strict bool, bucketFunc func(b *bolt.Bucket) error) func(tx *bolt.Tx) error {
+	return func(tx *bolt.Tx) (err error) {
+		var b *bolt.Bucket
+		if b = GetBucket(tx, path); b == nil {
+			if strict {
+				err = &BucketNotFoundError{Path: path}
+			}
+			return
 		}
+		err = bucketFunc(b)
 		return
 	}
-	err = db.Update(txf)
-	return
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/momokatte/go-boltdb-bucketeer"
+)
+
+const formatNames = "string, hex, json, uint64, int64, varint, uvarint, tuple"
+
+/*
+format converts keys and values between their byte form and a command-line representation.
+*/
+type format struct {
+	parse  func(s string) ([]byte, error)
+	format func(b []byte) (string, error)
+}
+
+var formats = map[string]format{
+	"string": {
+		parse: func(s string) ([]byte, error) {
+			return []byte(s), nil
+		},
+		format: func(b []byte) (string, error) {
+			return string(b), nil
+		},
+	},
+	"hex": {
+		parse: hex.DecodeString,
+		format: func(b []byte) (string, error) {
+			return hex.EncodeToString(b), nil
+		},
+	},
+	"json": {
+		parse: func(s string) ([]byte, error) {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, []byte(s)); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		format: func(b []byte) (string, error) {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, b); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		},
+	},
+	"uint64": {
+		parse: func(s string) ([]byte, error) {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return bucketeer.NewUint64Key(n).KeyBytes(), nil
+		},
+		format: func(b []byte) (string, error) {
+			n, err := bucketeer.DecodeUint64Key(b)
+			return strconv.FormatUint(uint64(n), 10), err
+		},
+	},
+	"int64": {
+		parse: func(s string) ([]byte, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return bucketeer.NewInt64Key(n).KeyBytes(), nil
+		},
+		format: func(b []byte) (string, error) {
+			n, err := bucketeer.DecodeInt64Key(b)
+			return strconv.FormatInt(int64(n), 10), err
+		},
+	},
+	"varint": {
+		parse: func(s string) ([]byte, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return bucketeer.VarintCodec.Marshal(n)
+		},
+		format: func(b []byte) (string, error) {
+			var n int64
+			err := bucketeer.VarintCodec.Unmarshal(b, &n)
+			return strconv.FormatInt(n, 10), err
+		},
+	},
+	"uvarint": {
+		parse: func(s string) ([]byte, error) {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return bucketeer.VarintCodec.Marshal(n)
+		},
+		format: func(b []byte) (string, error) {
+			var n uint64
+			err := bucketeer.VarintCodec.Unmarshal(b, &n)
+			return strconv.FormatUint(n, 10), err
+		},
+	},
+	"tuple": {
+		parse: func(s string) ([]byte, error) {
+			return nil, errors.New("Tuple keys cannot be parsed from the command line; use hex")
+		},
+		format: func(b []byte) (string, error) {
+			k, err := bucketeer.DecodeTupleKey(b)
+			return fmt.Sprintf("%v", []interface{}(k)), err
+		},
+	},
+}
+
+/*
+valueFormats holds the value formats which differ from the key formats of the same name. Int64 keys are sign-flipped so that they sort, while int64 values are stored as plain big-endian two's complement, as PutInt64Value and IncrementInt64Value write them.
+*/
+var valueFormats = map[string]format{
+	"int64": {
+		parse: func(s string) ([]byte, error) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint64(b, uint64(n))
+			return b, nil
+		},
+		format: func(b []byte) (string, error) {
+			if len(b) != 8 {
+				return "", errors.New("Value is not 8 bytes")
+			}
+			return strconv.FormatInt(int64(binary.BigEndian.Uint64(b)), 10), nil
+		},
+	},
+}
+
+func lookupFormat(name string) (f format, err error) {
+	var ok bool
+	if f, ok = formats[name]; !ok {
+		err = fmt.Errorf("Unknown format %q; expected one of %s", name, formatNames)
+	}
+	return
+}
+
+func lookupValueFormat(name string) (f format, err error) {
+	var ok bool
+	if f, ok = valueFormats[name]; !ok {
+		f, err = lookupFormat(name)
+	}
+	return
+}
@@ -0,0 +1,293 @@
+/*
+Command bucketeer inspects and edits BoltDB files.
+
+Usage:
+
+	bucketeer [flags] <db-file> <command> [arguments]
+
+Commands:
+
+	ls <path>                 list the keys and nested buckets in a bucket; use [] for the top-level buckets
+	tree [path]               print the bucket tree beneath a path, or the whole file
+	get <path> <key>          print the value of a key
+	put <path> <key> <value>  set the value of a key, creating the path buckets if needed
+	rm <path> <key>           delete a key
+	stats <path>              print the stats of a bucket
+	dump <path>               print every key and value in a bucket
+
+Paths are written in the form printed by bucketeer.Path.String, e.g. "[root, branch, leaf]". The file is opened read-only unless the -w flag is provided, which put and rm require.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/momokatte/go-boltdb-bucketeer"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "bucketeer:", err.Error())
+		os.Exit(1)
+	}
+}
+
+type command struct {
+	args     int
+	optional bool
+	writable bool
+	run      func(cli *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"ls":    {args: 1, run: (*cli).ls},
+	"tree":  {args: 1, optional: true, run: (*cli).tree},
+	"get":   {args: 2, run: (*cli).get},
+	"put":   {args: 3, writable: true, run: (*cli).put},
+	"rm":    {args: 2, writable: true, run: (*cli).rm},
+	"stats": {args: 1, run: (*cli).stats},
+	"dump":  {args: 1, run: (*cli).dump},
+}
+
+type cli struct {
+	db     *bolt.DB
+	out    io.Writer
+	keys   format
+	values format
+}
+
+func run(args []string, out io.Writer) (err error) {
+	fs := flag.NewFlagSet("bucketeer", flag.ContinueOnError)
+	writable := fs.Bool("w", false, "open the file for writing")
+	keyFormat := fs.String("k", "string", "key format: "+formatNames)
+	valueFormat := fs.String("v", "string", "value format: "+formatNames)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: bucketeer [flags] <db-file> <ls|tree|get|put|rm|stats|dump> [arguments]")
+		fs.PrintDefaults()
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("Missing db file or command")
+	}
+
+	c := &cli{out: out}
+	if c.keys, err = lookupFormat(*keyFormat); err != nil {
+		return
+	}
+	if c.values, err = lookupValueFormat(*valueFormat); err != nil {
+		return
+	}
+
+	name, cmdArgs := fs.Arg(1), fs.Args()[2:]
+	cmd, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("Unknown command %q", name)
+	}
+	if len(cmdArgs) != cmd.args && !(cmd.optional && len(cmdArgs) < cmd.args) {
+		return fmt.Errorf("Command %q takes %d arguments", name, cmd.args)
+	}
+	if cmd.writable && !*writable {
+		return fmt.Errorf("Command %q requires the -w flag", name)
+	}
+
+	if !*writable {
+		// bolt would create a missing file before failing to open it read-only
+		if _, err = os.Stat(fs.Arg(0)); err != nil {
+			return
+		}
+	}
+	options := &bolt.Options{
+		Timeout:  time.Second,
+		ReadOnly: !*writable,
+	}
+	if c.db, err = bolt.Open(fs.Arg(0), 0600, options); err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := c.db.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	err = cmd.run(c, cmdArgs)
+	return
+}
+
+func (c *cli) ls(args []string) (err error) {
+	var path bucketeer.Path
+	if path, err = bucketeer.ParsePath(args[0]); err != nil {
+		return
+	}
+	txf := func(tx *bolt.Tx) (err error) {
+		var cur *bolt.Cursor
+		if len(path) == 0 {
+			cur = tx.Cursor()
+		} else {
+			var b *bolt.Bucket
+			if b, err = bucketeer.RequireBucket(tx, path); err != nil {
+				return
+			}
+			cur = b.Cursor()
+		}
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			if v == nil && string(k) == bucketeer.MetaBucketName {
+				continue
+			}
+			if v == nil {
+				fmt.Fprintf(c.out, "%s/\n", string(k))
+				continue
+			}
+			var ks string
+			if ks, err = c.keys.format(k); err != nil {
+				return
+			}
+			fmt.Fprintln(c.out, ks)
+		}
+		return
+	}
+	err = c.db.View(txf)
+	return
+}
+
+func (c *cli) tree(args []string) (err error) {
+	var path bucketeer.Path
+	if len(args) != 0 {
+		if path, err = bucketeer.ParsePath(args[0]); err != nil {
+			return
+		}
+	}
+	walkFunc := func(p bucketeer.Path, stats bolt.BucketStats, depth int) error {
+		indent := depth
+		if len(path) == 0 {
+			indent--
+		}
+		fmt.Fprintf(c.out, "%*s%s (%d keys)\n", indent*2, "", string(p[len(p)-1]), stats.KeyN)
+		return nil
+	}
+	err = bucketeer.Walk(c.db, path, walkFunc)
+	return
+}
+
+func (c *cli) get(args []string) (err error) {
+	var kf *bucketeer.Keyfarer
+	if kf, err = c.keyfarer(args[0], args[1]); err != nil {
+		return
+	}
+	vf := func(value []byte) (err error) {
+		var vs string
+		if vs, err = c.values.format(value); err != nil {
+			return
+		}
+		fmt.Fprintln(c.out, vs)
+		return
+	}
+	err = kf.ViewValue(vf)
+	return
+}
+
+func (c *cli) put(args []string) (err error) {
+	var kf *bucketeer.Keyfarer
+	if kf, err = c.keyfarer(args[0], args[1]); err != nil {
+		return
+	}
+	var value []byte
+	if value, err = c.values.parse(args[2]); err != nil {
+		return
+	}
+	var path bucketeer.Path
+	if path, err = bucketeer.ParsePath(args[0]); err != nil {
+		return
+	}
+	if err = bucketeer.EnsurePathBuckets(c.db, path); err != nil {
+		return
+	}
+	err = kf.PutByteValue(value)
+	return
+}
+
+func (c *cli) rm(args []string) (err error) {
+	var kf *bucketeer.Keyfarer
+	if kf, err = c.keyfarer(args[0], args[1]); err != nil {
+		return
+	}
+	var existed bool
+	if existed, err = kf.Exists(); err != nil {
+		return
+	}
+	if !existed {
+		return fmt.Errorf("Key %s does not exist", args[1])
+	}
+	err = kf.Delete()
+	return
+}
+
+func (c *cli) stats(args []string) (err error) {
+	var bb *bucketeer.Bucketeer
+	if bb, err = c.bucketeer(args[0]); err != nil {
+		return
+	}
+	var s bolt.BucketStats
+	if s, err = bb.GetBucketStats(); err != nil {
+		return
+	}
+	fmt.Fprintf(c.out, "keys: %d\n", s.KeyN)
+	fmt.Fprintf(c.out, "buckets: %d (%d inline)\n", s.BucketN, s.InlineBucketN)
+	fmt.Fprintf(c.out, "depth: %d\n", s.Depth)
+	fmt.Fprintf(c.out, "branch pages: %d (%d overflow, %d bytes allocated, %d bytes in use)\n", s.BranchPageN, s.BranchOverflowN, s.BranchAlloc, s.BranchInuse)
+	fmt.Fprintf(c.out, "leaf pages: %d (%d overflow, %d bytes allocated, %d bytes in use)\n", s.LeafPageN, s.LeafOverflowN, s.LeafAlloc, s.LeafInuse)
+	return
+}
+
+func (c *cli) dump(args []string) (err error) {
+	var bb *bucketeer.Bucketeer
+	if bb, err = c.bucketeer(args[0]); err != nil {
+		return
+	}
+	iterFunc := func(cur *bucketeer.Cursor) (err error) {
+		var ks, vs string
+		if ks, err = c.keys.format(cur.Key()); err != nil {
+			return
+		}
+		if vs, err = c.values.format(cur.Value()); err != nil {
+			return
+		}
+		fmt.Fprintf(c.out, "%s\t%s\n", ks, vs)
+		return
+	}
+	err = bb.Iterate(iterFunc)
+	return
+}
+
+func (c *cli) bucketeer(pathArg string) (bb *bucketeer.Bucketeer, err error) {
+	var path bucketeer.Path
+	if path, err = bucketeer.ParsePath(pathArg); err != nil {
+		return
+	}
+	if len(path) == 0 {
+		err = errors.New("Path must have at least one element")
+		return
+	}
+	bb = bucketeer.ForPath(c.db, path).WithStrict(true)
+	return
+}
+
+func (c *cli) keyfarer(pathArg string, keyArg string) (kf *bucketeer.Keyfarer, err error) {
+	var bb *bucketeer.Bucketeer
+	if bb, err = c.bucketeer(pathArg); err != nil {
+		return
+	}
+	var key []byte
+	if key, err = c.keys.parse(keyArg); err != nil {
+		return
+	}
+	kf = bb.ForByteKey(key)
+	return
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/momokatte/go-boltdb-bucketeer"
+)
+
+func runOutput(t *testing.T, args ...string) string {
+	var out bytes.Buffer
+	if err := run(args, &out); err != nil {
+		t.Fatalf("Expected %v to succeed, got %s\n", args, err.Error())
+	}
+	return out.String()
+}
+
+func TestRun(t *testing.T) {
+
+	db := filepath.Join(t.TempDir(), "test.db")
+
+	runOutput(t, "-w", db, "put", "[root, leaf]", "a", "alpha")
+	runOutput(t, "-w", db, "put", "[root, leaf]", "b", "beta")
+	runOutput(t, "-w", "-k", "uint64", "-v", "int64", db, "put", "[root]", "7", "-3")
+
+	if out := runOutput(t, db, "ls", "[]"); out != "root/\n" {
+		t.Fatalf("Expected root/, got %q\n", out)
+	}
+	if out := runOutput(t, "-k", "uint64", db, "ls", "[root]"); out != "7\nleaf/\n" {
+		t.Fatalf("Expected 7 and leaf/, got %q\n", out)
+	}
+	if out := runOutput(t, db, "ls", "[root, leaf]"); out != "a\nb\n" {
+		t.Fatalf("Expected a and b, got %q\n", out)
+	}
+	if out := runOutput(t, db, "get", "[root, leaf]", "b"); out != "beta\n" {
+		t.Fatalf("Expected beta, got %q\n", out)
+	}
+	if out := runOutput(t, "-k", "uint64", "-v", "int64", db, "get", "[root]", "7"); out != "-3\n" {
+		t.Fatalf("Expected -3, got %q\n", out)
+	}
+	if out := runOutput(t, db, "dump", "[root, leaf]"); out != "a\talpha\nb\tbeta\n" {
+		t.Fatalf("Expected a and b with their values, got %q\n", out)
+	}
+	if out := runOutput(t, db, "tree"); out != "root (4 keys)\n  leaf (2 keys)\n" {
+		t.Fatalf("Expected the bucket tree, got %q\n", out)
+	}
+
+	runOutput(t, "-w", db, "rm", "[root, leaf]", "a")
+	if out := runOutput(t, db, "ls", "[root, leaf]"); out != "b\n" {
+		t.Fatalf("Expected b, got %q\n", out)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"-w", db, "rm", "[root, leaf]", "a"}, &out); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("Expected an error removing a missing key, got %v\n", err)
+	}
+	if err := run([]string{db, "get", "[root, leaf]", "a"}, &out); err == nil {
+		t.Fatal("Expected an error getting a missing key")
+	}
+}
+
+func TestRunReadOnly(t *testing.T) {
+
+	db := filepath.Join(t.TempDir(), "test.db")
+	runOutput(t, "-w", db, "put", "[root]", "a", "alpha")
+
+	var out bytes.Buffer
+	for _, args := range [][]string{
+		{db, "put", "[root]", "a", "changed"},
+		{db, "rm", "[root]", "a"},
+	} {
+		if err := run(args, &out); err == nil || !strings.Contains(err.Error(), "-w flag") {
+			t.Fatalf("Expected %v to require the -w flag, got %v\n", args, err)
+		}
+	}
+	if out := runOutput(t, db, "get", "[root]", "a"); out != "alpha\n" {
+		t.Fatalf("Expected alpha to be unchanged, got %q\n", out)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if err := run([]string{missing, "ls", "[]"}, &out); err == nil {
+		t.Fatal("Expected an error opening a missing file read-only")
+	}
+	if err := run([]string{db, "frobnicate", "[root]"}, &out); err == nil {
+		t.Fatal("Expected an error for an unknown command")
+	}
+}
+
+func TestRunHidesMetaBucket(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "test.db")
+	db, err := bolt.Open(filename, 0600, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	users := bucketeer.New(db, "users")
+	users.EnsurePathBuckets()
+	users.ForStringKey("u1").PutStringValue("alice")
+	byValue := func(v *string) (bucketeer.Key, bool) {
+		return bucketeer.NewStringKey(*v), true
+	}
+	if err = bucketeer.AddIndex(users, "name", bucketeer.RawCodec, byValue); err != nil {
+		t.Fatal(err.Error())
+	}
+	db.Close()
+
+	if out := runOutput(t, filename, "ls", "[users]"); out != "u1\n" {
+		t.Fatalf("Expected u1, got %q\n", out)
+	}
+	if out := runOutput(t, filename, "tree"); out != "users (1 keys)\n" {
+		t.Fatalf("Expected the bucket tree, got %q\n", out)
+	}
+}
+
+func TestRunInt64Values(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "test.db")
+	db, err := bolt.Open(filename, 0600, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	counters := bucketeer.New(db, "counters")
+	counters.EnsurePathBuckets()
+	counters.ForStringKey("hits").IncrementInt64Value(5)
+	counters.ForInt64Key(-2).PutInt64Value(-7)
+	db.Close()
+
+	if out := runOutput(t, "-v", "int64", filename, "get", "[counters]", "hits"); out != "5\n" {
+		t.Fatalf("Expected 5, got %q\n", out)
+	}
+	if out := runOutput(t, "-k", "int64", "-v", "int64", filename, "get", "[counters]", "-2"); out != "-7\n" {
+		t.Fatalf("Expected -7, got %q\n", out)
+	}
+
+	runOutput(t, "-w", "-v", "int64", filename, "put", "[counters]", "hits", "-9")
+	if db, err = bolt.Open(filename, 0600, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+	var n int64
+	if n, err = bucketeer.New(db, "counters").ForStringKey("hits").GetInt64Value(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if n != -9 {
+		t.Fatalf("Expected -9, got %d\n", n)
+	}
+}
@@ -0,0 +1,244 @@
+package bucketeer
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+Codec converts values to and from their byte form in BoltDB.
+*/
+type Codec interface {
+	Marshal(valueObj interface{}) ([]byte, error)
+	Unmarshal(value []byte, valueObj interface{}) error
+}
+
+var (
+	// JsonCodec stores values in their JSON form.
+	JsonCodec Codec = jsonCodec{}
+	// TextCodec stores values which implement encoding.TextMarshaler and encoding.TextUnmarshaler.
+	TextCodec Codec = textCodec{}
+	// BinaryCodec stores values which implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler.
+	BinaryCodec Codec = binaryCodec{}
+	// VarintCodec stores integers using variable-length encoding. Signed integers use zig-zag encoding and unsigned integers do not, so a value must be read back into a type of the same signedness.
+	VarintCodec Codec = varintCodec{}
+	// RawCodec stores byte slices and strings as-is.
+	RawCodec Codec = rawCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(valueObj interface{}) ([]byte, error) {
+	return json.Marshal(valueObj)
+}
+
+func (jsonCodec) Unmarshal(value []byte, valueObj interface{}) error {
+	return json.Unmarshal(value, valueObj)
+}
+
+type textCodec struct{}
+
+func (textCodec) Marshal(valueObj interface{}) (value []byte, err error) {
+	m, ok := valueObj.(encoding.TextMarshaler)
+	if !ok {
+		err = fmt.Errorf("Type %T does not implement encoding.TextMarshaler", valueObj)
+		return
+	}
+	value, err = m.MarshalText()
+	return
+}
+
+func (textCodec) Unmarshal(value []byte, valueObj interface{}) (err error) {
+	u, ok := valueObj.(encoding.TextUnmarshaler)
+	if !ok {
+		err = fmt.Errorf("Type %T does not implement encoding.TextUnmarshaler", valueObj)
+		return
+	}
+	err = u.UnmarshalText(value)
+	return
+}
+
+type binaryCodec struct{}
+
+func (binaryCodec) Marshal(valueObj interface{}) (value []byte, err error) {
+	m, ok := valueObj.(encoding.BinaryMarshaler)
+	if !ok {
+		err = fmt.Errorf("Type %T does not implement encoding.BinaryMarshaler", valueObj)
+		return
+	}
+	value, err = m.MarshalBinary()
+	return
+}
+
+func (binaryCodec) Unmarshal(value []byte, valueObj interface{}) (err error) {
+	u, ok := valueObj.(encoding.BinaryUnmarshaler)
+	if !ok {
+		err = fmt.Errorf("Type %T does not implement encoding.BinaryUnmarshaler", valueObj)
+		return
+	}
+	err = u.UnmarshalBinary(value)
+	return
+}
+
+type varintCodec struct{}
+
+func (varintCodec) Marshal(valueObj interface{}) (value []byte, err error) {
+	v := make([]byte, binary.MaxVarintLen64)
+	var l int
+	switch n := valueObj.(type) {
+	case int:
+		l = binary.PutVarint(v, int64(n))
+	case int8:
+		l = binary.PutVarint(v, int64(n))
+	case int16:
+		l = binary.PutVarint(v, int64(n))
+	case int32:
+		l = binary.PutVarint(v, int64(n))
+	case int64:
+		l = binary.PutVarint(v, n)
+	case uint:
+		l = binary.PutUvarint(v, uint64(n))
+	case uint8:
+		l = binary.PutUvarint(v, uint64(n))
+	case uint16:
+		l = binary.PutUvarint(v, uint64(n))
+	case uint32:
+		l = binary.PutUvarint(v, uint64(n))
+	case uint64:
+		l = binary.PutUvarint(v, n)
+	default:
+		err = fmt.Errorf("Type %T is not an integer type", valueObj)
+		return
+	}
+	value = v[:l]
+	return
+}
+
+func (varintCodec) Unmarshal(value []byte, valueObj interface{}) (err error) {
+	switch p := valueObj.(type) {
+	case *int, *int8, *int16, *int32, *int64:
+		var n int64
+		if n, err = decodeVarintValue(value); err != nil {
+			return
+		}
+		err = setInt(p, n)
+	case *uint, *uint8, *uint16, *uint32, *uint64:
+		var n uint64
+		if n, err = decodeUvarintValue(value); err != nil {
+			return
+		}
+		err = setUint(p, n)
+	default:
+		err = fmt.Errorf("Type %T is not a pointer to an integer type", valueObj)
+	}
+	return
+}
+
+func setInt(p interface{}, n int64) (err error) {
+	switch p := p.(type) {
+	case *int:
+		*p = int(n)
+		if int64(*p) != n {
+			err = fmt.Errorf("Value %d overflows int", n)
+		}
+	case *int8:
+		*p = int8(n)
+		if int64(*p) != n {
+			err = fmt.Errorf("Value %d overflows int8", n)
+		}
+	case *int16:
+		*p = int16(n)
+		if int64(*p) != n {
+			err = fmt.Errorf("Value %d overflows int16", n)
+		}
+	case *int32:
+		*p = int32(n)
+		if int64(*p) != n {
+			err = fmt.Errorf("Value %d overflows int32", n)
+		}
+	case *int64:
+		*p = n
+	}
+	return
+}
+
+func setUint(p interface{}, n uint64) (err error) {
+	switch p := p.(type) {
+	case *uint:
+		*p = uint(n)
+		if uint64(*p) != n {
+			err = fmt.Errorf("Value %d overflows uint", n)
+		}
+	case *uint8:
+		*p = uint8(n)
+		if uint64(*p) != n {
+			err = fmt.Errorf("Value %d overflows uint8", n)
+		}
+	case *uint16:
+		*p = uint16(n)
+		if uint64(*p) != n {
+			err = fmt.Errorf("Value %d overflows uint16", n)
+		}
+	case *uint32:
+		*p = uint32(n)
+		if uint64(*p) != n {
+			err = fmt.Errorf("Value %d overflows uint32", n)
+		}
+	case *uint64:
+		*p = n
+	}
+	return
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(valueObj interface{}) (value []byte, err error) {
+	switch v := valueObj.(type) {
+	case []byte:
+		value = v
+	case string:
+		value = []byte(v)
+	default:
+		err = fmt.Errorf("Type %T is not a byte slice or string", valueObj)
+	}
+	return
+}
+
+func (rawCodec) Unmarshal(value []byte, valueObj interface{}) (err error) {
+	switch p := valueObj.(type) {
+	case *[]byte:
+		*p = make([]byte, len(value))
+		copy(*p, value)
+	case *string:
+		*p = string(value)
+	default:
+		err = fmt.Errorf("Type %T is not a pointer to a byte slice or string", valueObj)
+	}
+	return
+}
+
+/*
+PutValue marshals the provided object with the provided Codec and sets it as the value for the key.
+*/
+func PutValue(b *bolt.Bucket, key []byte, valueObj interface{}, codec Codec) (err error) {
+	var value []byte
+	if value, err = codec.Marshal(valueObj); err != nil {
+		return
+	}
+	err = b.Put(key, value)
+	return
+}
+
+/*
+UnmarshalValue gets the key's value and unmarshals it into the provided object with the provided Codec.
+*/
+func UnmarshalValue(b *bolt.Bucket, key []byte, valueObj interface{}, codec Codec) (err error) {
+	if value := b.Get(key); value != nil {
+		err = codec.Unmarshal(value, valueObj)
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package bucketeer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestVarintCodec(t *testing.T) {
+
+	v, err := VarintCodec.Marshal(int64(-3))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []byte{5}
+	if !bytes.Equal(expected, v) {
+		t.Fatalf("Expected %v, got %v\n", expected, v)
+	}
+
+	var i8 int8
+	if err = VarintCodec.Unmarshal(v, &i8); err != nil {
+		t.Fatal(err.Error())
+	}
+	if i8 != -3 {
+		t.Fatalf("Expected -3, got %d\n", i8)
+	}
+
+	if v, err = VarintCodec.Marshal(uint16(300)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = VarintCodec.Unmarshal(v, &i8); err == nil {
+		t.Fatal("Expected an error unmarshaling 300 into an int8")
+	}
+}
+
+func TestCodecValues(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test").WithCodec(TextCodec)
+	b.EnsurePathBuckets()
+
+	expected := time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err = b.ForStringKey("t").Put(expected, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var s string
+	if err = b.ForStringKey("t").Get(&s, RawCodec); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s != "2012-01-01T00:00:00Z" {
+		t.Fatalf("Expected 2012-01-01T00:00:00Z, got %s\n", s)
+	}
+
+	var actual time.Time
+	if err = b.ForStringKey("t").Get(&actual, nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !expected.Equal(actual) {
+		t.Fatalf("Expected %v, got %v\n", expected, actual)
+	}
+}
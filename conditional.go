@@ -0,0 +1,123 @@
+package bucketeer
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+CompareAndSwap sets the value for the key to the new value only if its current value equals the old value. A nil old value means the key must not exist, and a nil new value deletes the key. If the current value does not match, a ConditionFailedError is returned.
+*/
+func (kf *Keyfarer) CompareAndSwap(old, new []byte) error {
+	bf := func(b *bolt.Bucket) error {
+		current := b.Get(kf.key)
+		if (old == nil) != (current == nil) || !bytes.Equal(old, current) {
+			return kf.conditionFailed(current)
+		}
+		return putOrDelete(b, kf.key, new)
+	}
+	return kf.update(bf)
+}
+
+/*
+PutIfAbsent sets the value for the key only if the key does not exist. Otherwise a ConditionFailedError is returned.
+*/
+func (kf *Keyfarer) PutIfAbsent(value []byte) error {
+	bf := func(b *bolt.Bucket) error {
+		if current := b.Get(kf.key); current != nil {
+			return kf.conditionFailed(current)
+		}
+		return b.Put(kf.key, value)
+	}
+	return kf.update(bf)
+}
+
+/*
+PutIfPresent sets the value for the key only if the key already exists. Otherwise a ConditionFailedError is returned.
+*/
+func (kf *Keyfarer) PutIfPresent(value []byte) error {
+	bf := func(b *bolt.Bucket) error {
+		if b.Get(kf.key) == nil {
+			return kf.conditionFailed(nil)
+		}
+		return b.Put(kf.key, value)
+	}
+	return kf.update(bf)
+}
+
+/*
+CompareAndSwapValue sets the value for the key to the new object only if the current value, unmarshaled with the provided Codec, is deeply equal to the old object. This allows values to be compared semantically rather than bytewise. A nil old object means the key must not exist, and a nil new object deletes the key; a nil pointer is treated as nil. If the Codec is nil, the Bucketeer's default Codec is used. If the current value does not match, a ConditionFailedError is returned.
+*/
+func (kf *Keyfarer) CompareAndSwapValue(oldObj, newObj interface{}, codec Codec) error {
+	codec = kf.codecOrDefault(codec)
+	oldObj, newObj = nilPointerAsNil(oldObj), nilPointerAsNil(newObj)
+	bf := func(b *bolt.Bucket) (err error) {
+		current := b.Get(kf.key)
+		if oldObj == nil || current == nil {
+			if oldObj != nil || current != nil {
+				return kf.conditionFailed(current)
+			}
+		} else {
+			var equal bool
+			if equal, err = valueEquals(current, oldObj, codec); err != nil {
+				return
+			}
+			if !equal {
+				return kf.conditionFailed(current)
+			}
+		}
+		if newObj == nil {
+			return b.Delete(kf.key)
+		}
+		return PutValue(b, kf.key, newObj, codec)
+	}
+	return kf.update(bf)
+}
+
+func (kf *Keyfarer) conditionFailed(current []byte) error {
+	var currentCopy []byte
+	if current != nil {
+		currentCopy = make([]byte, len(current))
+		copy(currentCopy, current)
+	}
+	return &ConditionFailedError{
+		Path:    kf.bb.path,
+		Key:     kf.key,
+		Current: currentCopy,
+	}
+}
+
+/*
+nilPointerAsNil returns nil if the object is a nil pointer, so that a typed nil such as a nil *User is treated like an untyped nil.
+*/
+func nilPointerAsNil(obj interface{}) interface{} {
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return obj
+}
+
+/*
+valueEquals unmarshals the value into a new object of the same type as the provided object and reports whether the two are deeply equal. If the provided object is a pointer, the values they point to are compared.
+*/
+func valueEquals(value []byte, obj interface{}, codec Codec) (equal bool, err error) {
+	expected := reflect.ValueOf(obj)
+	if expected.Kind() == reflect.Ptr {
+		expected = expected.Elem()
+	}
+	actual := reflect.New(expected.Type())
+	if err = codec.Unmarshal(value, actual.Interface()); err != nil {
+		return
+	}
+	equal = reflect.DeepEqual(expected.Interface(), actual.Elem().Interface())
+	return
+}
+
+func putOrDelete(b *bolt.Bucket, key []byte, value []byte) error {
+	if value == nil {
+		return b.Delete(key)
+	}
+	return b.Put(key, value)
+}
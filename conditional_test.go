@@ -0,0 +1,91 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	kf := b.ForStringKey("k1")
+
+	if err = kf.PutIfPresent([]byte("v0")); !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("Expected ErrConditionFailed, got %v\n", err)
+	}
+	if err = kf.PutIfAbsent([]byte("v1")); err != nil {
+		t.Fatal(err.Error())
+	}
+	err = kf.PutIfAbsent([]byte("v2"))
+	var condErr *ConditionFailedError
+	if !errors.As(err, &condErr) {
+		t.Fatalf("Expected ConditionFailedError, got %v\n", err)
+	}
+	if expected := []byte("v1"); !bytes.Equal(expected, condErr.Current) {
+		t.Fatalf("Expected current value %s, got %s\n", expected, condErr.Current)
+	}
+
+	if err = kf.CompareAndSwap([]byte("v2"), []byte("v3")); !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("Expected ErrConditionFailed, got %v\n", err)
+	}
+	if err = kf.CompareAndSwap([]byte("v1"), []byte("v3")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = kf.CompareAndSwap([]byte("v3"), nil); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ := kf.GetByteValue(); v != nil {
+		t.Fatalf("Expected key to be deleted, got %s\n", v)
+	}
+}
+
+func TestCompareAndSwapValue(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	kf := b.ForStringKey("k1")
+
+	// stored with different key order and whitespace than json.Marshal produces
+	kf.PutByteValue([]byte(`{ "b": 2, "a": 1 }`))
+
+	old := map[string]int{"a": 1, "b": 2}
+	if err = kf.CompareAndSwapValue(old, map[string]int{"a": 3}, JsonCodec); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = kf.CompareAndSwapValue(old, map[string]int{"a": 4}, JsonCodec); !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("Expected ErrConditionFailed, got %v\n", err)
+	}
+
+	// nil pointers are treated as nil
+	type pair struct{ A, B int }
+	var none *pair
+	kf2 := b.ForStringKey("k2")
+	if err = kf2.CompareAndSwapValue(none, &pair{A: 1}, JsonCodec); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = kf2.CompareAndSwapValue(none, &pair{A: 2}, JsonCodec); !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("Expected ErrConditionFailed, got %v\n", err)
+	}
+	if err = kf2.CompareAndSwapValue(&pair{A: 1}, none, JsonCodec); err != nil {
+		t.Fatal(err.Error())
+	}
+	if exists, _ := kf2.Exists(); exists {
+		t.Fatal("Expected a nil pointer to delete the key")
+	}
+}
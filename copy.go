@@ -0,0 +1,152 @@
+package bucketeer
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ErrDestinationInSource is returned when copying or moving a bucket to a path nested beneath itself in the same database.
+*/
+var ErrDestinationInSource = errors.New("Destination path is inside the source path")
+
+/*
+CopyBucket deep-copies the bucket at the source path, including its values, nested buckets and sequences, to the destination path. The databases may be the same or different. The destination's parent buckets are created as needed, but the destination bucket itself must not exist; if it does, bolt.ErrBucketExists is returned.
+*/
+func CopyBucket(srcDB *bolt.DB, srcPath Path, dstDB *bolt.DB, dstPath Path) error {
+	if err := checkCopyPaths(srcDB, srcPath, dstDB, dstPath); err != nil {
+		return err
+	}
+	if srcDB == dstDB {
+		txf := func(tx *bolt.Tx) error {
+			return copyBucketTx(tx, srcPath, tx, dstPath)
+		}
+		return dstDB.Update(txf)
+	}
+	// the source transaction stays open until the copy is committed, because the values refer to its memory
+	txf := func(srcTx *bolt.Tx) error {
+		dstTxf := func(dstTx *bolt.Tx) error {
+			return copyBucketTx(srcTx, srcPath, dstTx, dstPath)
+		}
+		return dstDB.Update(dstTxf)
+	}
+	return srcDB.View(txf)
+}
+
+/*
+MoveBucket copies the bucket at the source path to the destination path as CopyBucket does, then deletes the source bucket. Within a single database, the copy and delete are made in one transaction. Between two databases, the source bucket is only deleted after the copy has been committed; if deleting it fails, the bucket is left in both databases rather than lost.
+*/
+func MoveBucket(srcDB *bolt.DB, srcPath Path, dstDB *bolt.DB, dstPath Path) error {
+	if err := checkCopyPaths(srcDB, srcPath, dstDB, dstPath); err != nil {
+		return err
+	}
+	if srcDB == dstDB {
+		txf := func(tx *bolt.Tx) (err error) {
+			if err = copyBucketTx(tx, srcPath, tx, dstPath); err != nil {
+				return
+			}
+			err = deleteBucketTx(tx, srcPath)
+			return
+		}
+		return srcDB.Update(txf)
+	}
+	txf := func(srcTx *bolt.Tx) (err error) {
+		dstTxf := func(dstTx *bolt.Tx) error {
+			return copyBucketTx(srcTx, srcPath, dstTx, dstPath)
+		}
+		if err = dstDB.Update(dstTxf); err != nil {
+			return
+		}
+		err = deleteBucketTx(srcTx, srcPath)
+		return
+	}
+	return srcDB.Update(txf)
+}
+
+/*
+RenameNestedBucket moves a nested bucket and everything beneath it to a new name in the current bucket. If a nested bucket with the new name exists, bolt.ErrBucketExists is returned.
+*/
+func (bb *Bucketeer) RenameNestedBucket(oldName string, newName string) error {
+	srcPath, dstPath := bb.path.Nest(oldName), bb.path.Nest(newName)
+	if err := checkCopyPaths(bb.db, srcPath, bb.db, dstPath); err != nil {
+		return err
+	}
+	txf := func(tx *bolt.Tx) (err error) {
+		if err = copyBucketTx(tx, srcPath, tx, dstPath); err != nil {
+			return
+		}
+		err = deleteBucketTx(tx, srcPath)
+		return
+	}
+	return bb.updateTx(txf)
+}
+
+func checkCopyPaths(srcDB *bolt.DB, srcPath Path, dstDB *bolt.DB, dstPath Path) error {
+	if len(srcPath) == 0 || len(dstPath) == 0 {
+		panic("Path must have at least one element")
+	}
+	if srcDB == dstDB && len(dstPath) >= len(srcPath) && pathEqual(dstPath[:len(srcPath)], srcPath) {
+		return ErrDestinationInSource
+	}
+	return nil
+}
+
+func copyBucketTx(srcTx *bolt.Tx, srcPath Path, dstTx *bolt.Tx, dstPath Path) (err error) {
+	var src, dst *bolt.Bucket
+	if src, err = RequireBucket(srcTx, srcPath); err != nil {
+		return
+	}
+	if len(dstPath) == 1 {
+		dst, err = dstTx.CreateBucket(dstPath[0])
+	} else {
+		var parent *bolt.Bucket
+		if parent, err = ensureBucket(dstTx, dstPath[:len(dstPath)-1]); err != nil {
+			return
+		}
+		dst, err = parent.CreateBucket(dstPath[len(dstPath)-1])
+	}
+	if err != nil {
+		return
+	}
+	err = copyBucket(src, dst)
+	return
+}
+
+/*
+copyBucket copies the sequence, values and nested buckets of the source bucket into the destination bucket, overwriting any existing keys.
+*/
+func copyBucket(src *bolt.Bucket, dst *bolt.Bucket) (err error) {
+	if err = dst.SetSequence(src.Sequence()); err != nil {
+		return
+	}
+	c := src.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v != nil {
+			if err = dst.Put(k, v); err != nil {
+				return
+			}
+			continue
+		}
+		var nested *bolt.Bucket
+		if nested, err = dst.CreateBucketIfNotExists(k); err != nil {
+			return
+		}
+		if err = copyBucket(src.Bucket(k), nested); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func deleteBucketTx(tx *bolt.Tx, path Path) (err error) {
+	if len(path) == 1 {
+		return tx.DeleteBucket(path[0])
+	}
+	var parent *bolt.Bucket
+	if parent, err = RequireBucket(tx, path[:len(path)-1]); err != nil {
+		return
+	}
+	err = parent.DeleteBucket(path[len(path)-1])
+	return
+}
@@ -0,0 +1,75 @@
+package bucketeer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCopyMoveBucket(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+	other, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer other.Close()
+
+	src := New(db, "src")
+	src.EnsurePathBuckets()
+	src.EnsureNestedBucket("nested")
+	src.UpdateWithSequence(func(b *bolt.Bucket, sequence uint64) error { return nil })
+	src.ForStringKey("k1").PutStringValue("v1")
+	src.InNestedBucket("nested").ForStringKey("k2").PutStringValue("v2")
+
+	var expected bytes.Buffer
+	src.Export(&expected, ExportOptions{TextKeys: true, TextValues: true})
+	exportEquals := func(bb *Bucketeer) {
+		var actual bytes.Buffer
+		if err := bb.Export(&actual, ExportOptions{TextKeys: true, TextValues: true}); err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual.String() != expected.String() {
+			t.Fatalf("Expected %s, got %s\n", expected.String(), actual.String())
+		}
+	}
+
+	if err = CopyBucket(db, NewPath("src"), db, NewPath("a", "copy")); err != nil {
+		t.Fatal(err.Error())
+	}
+	exportEquals(New(db, "a", "copy"))
+	exportEquals(src)
+
+	if err = CopyBucket(db, NewPath("src"), db, NewPath("a", "copy")); err != bolt.ErrBucketExists {
+		t.Fatalf("Expected %v, got %v\n", bolt.ErrBucketExists, err)
+	}
+	if err = CopyBucket(db, NewPath("src"), db, NewPath("src", "nested", "copy")); err != ErrDestinationInSource {
+		t.Fatalf("Expected %v, got %v\n", ErrDestinationInSource, err)
+	}
+
+	if err = MoveBucket(db, NewPath("src"), other, NewPath("moved")); err != nil {
+		t.Fatal(err.Error())
+	}
+	exportEquals(New(other, "moved"))
+	if err = src.WithStrict(true).View(func(b *bolt.Bucket) error { return nil }); err == nil {
+		t.Fatal("Expected the source bucket to be deleted")
+	}
+
+	a := New(db, "a")
+	if err = a.RenameNestedBucket("copy", "renamed"); err != nil {
+		t.Fatal(err.Error())
+	}
+	exportEquals(a.InNestedBucket("renamed"))
+	var stats bolt.BucketStats
+	if stats, err = a.GetBucketStats(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if stats.KeyN != 4 {
+		t.Fatalf("Expected 4 keys (two values and two nested buckets) after the rename, got %d\n", stats.KeyN)
+	}
+}
@@ -0,0 +1,340 @@
+package bucketeer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ErrStopIteration can be returned from an iteration function to end the iteration early without an error being returned to the caller.
+*/
+var ErrStopIteration = errors.New("Stop iteration")
+
+/*
+Cursor wraps a bolt.Cursor to walk the key-value pairs of a bucket in either direction. Nested buckets and expired keys are skipped. The key and value byte slices are only valid within the scope of the transaction.
+*/
+type Cursor struct {
+	c       *bolt.Cursor
+	reverse bool
+	started bool
+	done    bool
+	key     []byte
+	value   []byte
+	opts    ScanOptions
+	from    []byte
+	to      []byte
+	count   int
+	ttl     *bolt.Bucket
+	now     int64
+}
+
+/*
+NewCursor creates a Cursor for the provided bucket. The cursor is positioned before the first (or, when reverse is true, after the last) key-value pair.
+*/
+func NewCursor(b *bolt.Bucket, reverse bool) (c *Cursor) {
+	c = &Cursor{
+		c:       b.Cursor(),
+		reverse: reverse,
+		ttl:     ttlBucket(b),
+		now:     timeNow().UnixNano(),
+	}
+	return
+}
+
+/*
+Next advances the cursor to the next key-value pair and reports whether one was found.
+*/
+func (c *Cursor) Next() bool {
+	if c.done {
+		return false
+	}
+	var k, v []byte
+	if !c.started {
+		c.started = true
+		k, v = c.first()
+	} else {
+		k, v = c.step()
+	}
+	for k != nil && (v == nil || c.expired(k)) {
+		k, v = c.step()
+	}
+	if k == nil || !c.inRange(k) || (c.opts.Limit > 0 && c.count >= c.opts.Limit) {
+		c.done = true
+		k, v = nil, nil
+	} else {
+		c.count++
+	}
+	c.key, c.value = k, v
+	return k != nil
+}
+
+/*
+expired reports whether the key has an expiry time which had passed when the cursor was created.
+*/
+func (c *Cursor) expired(k []byte) bool {
+	return expiredAt(c.ttl, k, c.now)
+}
+
+/*
+expiredAt reports whether the key has an expiry time in the provided expiry bucket, which may be nil, at or before the provided time.
+*/
+func expiredAt(ttl *bolt.Bucket, k []byte, now int64) bool {
+	if ttl == nil {
+		return false
+	}
+	var expiry Int64Key
+	if v := ttl.Get(k); v == nil || expiry.DecodeKey(v) != nil {
+		return false
+	}
+	return int64(expiry) <= now
+}
+
+func (c *Cursor) first() (k []byte, v []byte) {
+	if c.reverse {
+		if c.to == nil {
+			return c.c.Last()
+		}
+		if k, v = c.c.Seek(c.to); k == nil {
+			return c.c.Last()
+		}
+		if cmp := bytes.Compare(k, c.to); cmp > 0 || (cmp == 0 && c.opts.ToExclusive) {
+			return c.c.Prev()
+		}
+		return
+	}
+	if c.from == nil {
+		return c.c.First()
+	}
+	if k, v = c.c.Seek(c.from); k != nil && c.opts.FromExclusive && bytes.Equal(k, c.from) {
+		return c.c.Next()
+	}
+	return
+}
+
+func (c *Cursor) inRange(k []byte) bool {
+	if c.from != nil {
+		if cmp := bytes.Compare(k, c.from); cmp < 0 || (cmp == 0 && c.opts.FromExclusive) {
+			return false
+		}
+	}
+	if c.to != nil {
+		if cmp := bytes.Compare(k, c.to); cmp > 0 || (cmp == 0 && c.opts.ToExclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cursor) step() ([]byte, []byte) {
+	if c.reverse {
+		return c.c.Prev()
+	}
+	return c.c.Next()
+}
+
+/*
+Key returns the raw bytes of the current key.
+*/
+func (c *Cursor) Key() []byte {
+	return c.key
+}
+
+/*
+Value returns the raw bytes of the current value.
+*/
+func (c *Cursor) Value() []byte {
+	return c.value
+}
+
+/*
+ByteKey returns a copy of the current key as a ByteKey.
+*/
+func (c *Cursor) ByteKey() ByteKey {
+	return DecodeByteKey(c.key)
+}
+
+/*
+StringKey returns the current key as a StringKey.
+*/
+func (c *Cursor) StringKey() StringKey {
+	return DecodeStringKey(c.key)
+}
+
+/*
+Uint64Key decodes the current key as a Uint64Key. The key must be 8 bytes with the bits in big-endian ordering.
+*/
+func (c *Cursor) Uint64Key() (Uint64Key, error) {
+	return DecodeUint64Key(c.key)
+}
+
+/*
+Int64Key decodes the current key as an Int64Key. The key must be 8 bytes in the shifted, big-endian form written by Int64Key.
+*/
+func (c *Cursor) Int64Key() (Int64Key, error) {
+	return DecodeInt64Key(c.key)
+}
+
+/*
+UnmarshalTextKey unmarshals the current key into the provided object.
+*/
+func (c *Cursor) UnmarshalTextKey(keyObj encoding.TextUnmarshaler) error {
+	return UnmarshalTextKey(c.key, keyObj)
+}
+
+/*
+UnmarshalBinaryKey unmarshals the current key into the provided object.
+*/
+func (c *Cursor) UnmarshalBinaryKey(keyObj encoding.BinaryUnmarshaler) error {
+	return UnmarshalBinaryKey(c.key, keyObj)
+}
+
+/*
+UnmarshalJsonKey unmarshals the current key into the provided object.
+*/
+func (c *Cursor) UnmarshalJsonKey(keyObj interface{}) error {
+	return UnmarshalJsonKey(c.key, keyObj)
+}
+
+/*
+DecodeKey decodes the current key into the provided KeyDecoder.
+*/
+func (c *Cursor) DecodeKey(keyObj KeyDecoder) error {
+	return keyObj.DecodeKey(c.key)
+}
+
+/*
+ByteValue returns a copy of the current value.
+*/
+func (c *Cursor) ByteValue() (valueCopy []byte) {
+	valueCopy = make([]byte, len(c.value))
+	copy(valueCopy, c.value)
+	return
+}
+
+/*
+StringValue returns the current value as a string.
+*/
+func (c *Cursor) StringValue() string {
+	return string(c.value)
+}
+
+/*
+UnmarshalTextValue unmarshals the current value into the provided object.
+*/
+func (c *Cursor) UnmarshalTextValue(valueObj encoding.TextUnmarshaler) error {
+	return valueObj.UnmarshalText(c.value)
+}
+
+/*
+UnmarshalBinaryValue unmarshals the current value into the provided object.
+*/
+func (c *Cursor) UnmarshalBinaryValue(valueObj encoding.BinaryUnmarshaler) error {
+	return valueObj.UnmarshalBinary(c.value)
+}
+
+/*
+UnmarshalJsonValue unmarshals the current value into the provided object.
+*/
+func (c *Cursor) UnmarshalJsonValue(valueObj interface{}) error {
+	return json.Unmarshal(c.value, valueObj)
+}
+
+/*
+UnmarshalValue unmarshals the current value into the provided object with the provided Codec.
+*/
+func (c *Cursor) UnmarshalValue(valueObj interface{}, codec Codec) error {
+	return codec.Unmarshal(c.value, valueObj)
+}
+
+/*
+Int64Value converts the current value into an int64 value. The value must be 8 bytes with the bits in big-endian ordering.
+*/
+func (c *Cursor) Int64Value() (value int64, err error) {
+	var v uint64
+	if v, err = decodeUint64Value(c.value); err != nil {
+		return
+	}
+	value = int64(v)
+	return
+}
+
+/*
+Uint64Value converts the current value into a uint64 value. The value must be 8 bytes with the bits in big-endian ordering.
+*/
+func (c *Cursor) Uint64Value() (uint64, error) {
+	return decodeUint64Value(c.value)
+}
+
+/*
+VarintValue decodes the current value into an int64 value using variable-length decoding.
+*/
+func (c *Cursor) VarintValue() (int64, error) {
+	return decodeVarintValue(c.value)
+}
+
+/*
+UvarintValue decodes the current value into a uint64 value using variable-length decoding.
+*/
+func (c *Cursor) UvarintValue() (uint64, error) {
+	return decodeUvarintValue(c.value)
+}
+
+/*
+Cursor executes the provided function in a View transaction with a Cursor for the current bucket.
+*/
+func (bb *Bucketeer) Cursor(reverse bool, cursorFunc func(c *Cursor) error) error {
+	bf := func(b *bolt.Bucket) error {
+		return cursorFunc(NewCursor(b, reverse))
+	}
+	return bb.View(bf)
+}
+
+/*
+Iterate calls the provided function for each key-value pair in the current bucket, in key order. Nested buckets are skipped.
+*/
+func (bb *Bucketeer) Iterate(iterFunc func(c *Cursor) error) error {
+	return bb.ScanRange(nil, nil, ScanOptions{}, iterFunc)
+}
+
+/*
+IterateReverse calls the provided function for each key-value pair in the current bucket, in reverse key order. Nested buckets are skipped.
+*/
+func (bb *Bucketeer) IterateReverse(iterFunc func(c *Cursor) error) error {
+	return bb.ScanRange(nil, nil, ScanOptions{Reverse: true}, iterFunc)
+}
+
+/*
+CursorInBucket executes the provided function in a View transaction with a Cursor for the bucket.
+*/
+func CursorInBucket(db *bolt.DB, path Path, reverse bool, cursorFunc func(c *Cursor) error) error {
+	bf := func(b *bolt.Bucket) error {
+		return cursorFunc(NewCursor(b, reverse))
+	}
+	return ViewInBucket(db, path, bf)
+}
+
+/*
+IterateInBucket calls the provided function in a View transaction for each key-value pair in the bucket. Returning ErrStopIteration from the function ends the iteration without an error.
+*/
+func IterateInBucket(db *bolt.DB, path Path, reverse bool, iterFunc func(c *Cursor) error) error {
+	cf := func(c *Cursor) error {
+		return iterate(c, iterFunc)
+	}
+	return CursorInBucket(db, path, reverse, cf)
+}
+
+func iterate(c *Cursor, iterFunc func(c *Cursor) error) (err error) {
+	for c.Next() {
+		if err = iterFunc(c); err != nil {
+			break
+		}
+	}
+	if err == ErrStopIteration {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,180 @@
+package bucketeer
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestIterate(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	b.EnsureNestedBucket("nested")
+
+	for _, k := range []int64{3, -1, 2, -4} {
+		b.ForInt64Key(k).PutVarintValue(k * 10)
+	}
+
+	var keys []int64
+	iterFunc := func(c *Cursor) (err error) {
+		var k Int64Key
+		if k, err = c.Int64Key(); err != nil {
+			return
+		}
+		var v int64
+		if v, err = c.VarintValue(); err != nil {
+			return
+		}
+		if v != int64(k)*10 {
+			t.Fatalf("Expected %d, got %d\n", int64(k)*10, v)
+		}
+		keys = append(keys, int64(k))
+		return
+	}
+
+	if err = b.Iterate(iterFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []int64{-4, -1, 2, 3}
+	if !equalInt64s(expected, keys) {
+		t.Fatalf("Expected %v, got %v\n", expected, keys)
+	}
+
+	keys = nil
+	if err = b.IterateReverse(iterFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []int64{3, 2, -1, -4}
+	if !equalInt64s(expected, keys) {
+		t.Fatalf("Expected %v, got %v\n", expected, keys)
+	}
+}
+
+func TestIterateStop(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	for _, k := range []string{"a", "b", "c"} {
+		b.ForStringKey(k).PutStringValue(k)
+	}
+
+	var keys []string
+	iterFunc := func(c *Cursor) error {
+		keys = append(keys, string(c.StringKey()))
+		if len(keys) == 2 {
+			return ErrStopIteration
+		}
+		return nil
+	}
+	if err = b.Iterate(iterFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(keys) != 2 || keys[1] != "b" {
+		t.Fatalf("Expected [a b], got %v\n", keys)
+	}
+}
+
+func TestScanRange(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	for k := int64(-5); k <= 5; k++ {
+		b.ForInt64Key(k).PutStringValue("v")
+	}
+
+	var keys []int64
+	scanFunc := func(c *Cursor) (err error) {
+		var k Int64Key
+		if k, err = c.Int64Key(); err != nil {
+			return
+		}
+		keys = append(keys, int64(k))
+		return
+	}
+
+	tests := []struct {
+		from, to Key
+		opts     ScanOptions
+		expected []int64
+	}{
+		{NewInt64Key(-2), NewInt64Key(2), ScanOptions{}, []int64{-2, -1, 0, 1, 2}},
+		{NewInt64Key(-2), NewInt64Key(2), ScanOptions{FromExclusive: true, ToExclusive: true}, []int64{-1, 0, 1}},
+		{NewInt64Key(-2), NewInt64Key(2), ScanOptions{Reverse: true}, []int64{2, 1, 0, -1, -2}},
+		{NewInt64Key(-2), NewInt64Key(2), ScanOptions{Reverse: true, FromExclusive: true, ToExclusive: true}, []int64{1, 0, -1}},
+		{nil, NewInt64Key(-3), ScanOptions{}, []int64{-5, -4, -3}},
+		{NewInt64Key(3), nil, ScanOptions{Reverse: true}, []int64{5, 4, 3}},
+		{NewInt64Key(10), nil, ScanOptions{Reverse: true}, nil},
+		{nil, nil, ScanOptions{Limit: 2}, []int64{-5, -4}},
+	}
+	for i, test := range tests {
+		keys = nil
+		if err = b.ScanRange(test.from, test.to, test.opts, scanFunc); err != nil {
+			t.Fatal(err.Error())
+		}
+		if !equalInt64s(test.expected, keys) {
+			t.Fatalf("Case %d: expected %v, got %v\n", i, test.expected, keys)
+		}
+	}
+}
+
+func TestScanPrefix(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	for _, k := range []string{"a", "b/1", "b/2", "b/3", "c"} {
+		b.ForStringKey(k).PutStringValue("v")
+	}
+
+	var keys []string
+	scanFunc := func(c *Cursor) error {
+		keys = append(keys, string(c.StringKey()))
+		return nil
+	}
+
+	if err = b.ScanPrefix(NewStringKey("b/"), ScanOptions{Reverse: true, Limit: 2}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(keys) != 2 || keys[0] != "b/3" || keys[1] != "b/2" {
+		t.Fatalf("Expected [b/3 b/2], got %v\n", keys)
+	}
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package bucketeer
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+Delete removes the key and its value. Deleting a key which does not exist is not an error.
+*/
+func (kf *Keyfarer) Delete() (err error) {
+	_, err = kf.deleteKey()
+	return
+}
+
+/*
+Exists reports whether the key exists in the bucket. A missing key is not an error, even in strict mode.
+*/
+func (kf *Keyfarer) Exists() (exists bool, err error) {
+	if kf.err != nil {
+		err = kf.err
+		return
+	}
+	bf := func(b *bolt.Bucket) (err error) {
+		exists = b.Get(kf.key) != nil && !isExpired(b, kf.key)
+		return
+	}
+	err = kf.bb.View(bf)
+	return
+}
+
+/*
+GetAndDelete removes the key and returns a copy of the value it had, in the same Update transaction. If the key does not exist, the value is nil; in strict mode a KeyNotFoundError is returned instead.
+*/
+func (kf *Keyfarer) GetAndDelete() (value []byte, err error) {
+	bf := func(b *bolt.Bucket) (err error) {
+		if value = GetByteValue(b, kf.key); value == nil {
+			if kf.bb.strict {
+				err = &KeyNotFoundError{Path: kf.bb.path, Key: kf.key}
+			}
+			return
+		}
+		return b.Delete(kf.key)
+	}
+	err = kf.update(bf)
+	return
+}
+
+/*
+deleteKey removes the key and reports whether it existed.
+*/
+func (kf *Keyfarer) deleteKey() (existed bool, err error) {
+	bf := func(b *bolt.Bucket) error {
+		if existed = b.Get(kf.key) != nil; !existed {
+			return nil
+		}
+		return b.Delete(kf.key)
+	}
+	err = kf.update(bf)
+	return
+}
+
+/*
+DeleteKeys removes the provided keys in a single Update transaction and returns how many of them existed.
+*/
+func (bb *Bucketeer) DeleteKeys(keys ...Key) (count int, err error) {
+	txnFunc := func(t *BucketTx) (err error) {
+		count = 0
+		for _, k := range keys {
+			var existed bool
+			if existed, err = t.ForKey(k).deleteKey(); err != nil {
+				return
+			}
+			if existed {
+				count++
+			}
+		}
+		return
+	}
+	err = bb.Txn(txnFunc)
+	return
+}
+
+/*
+DeleteRange removes every key between the provided bounds in a single Update transaction and returns how many were removed. A nil Key leaves that end of the range open. Nested buckets are not removed. Expired keys in the range are removed along with their expiry times, but are not counted, and the Limit option only counts keys which have not expired.
+*/
+func (bb *Bucketeer) DeleteRange(from, to Key, opts ScanOptions) (count int, err error) {
+	var fromBytes, toBytes []byte
+	if fromBytes, err = encodeBound(from); err != nil {
+		return
+	}
+	if toBytes, err = encodeBound(to); err != nil {
+		return
+	}
+	limit := opts.Limit
+	opts.Limit = 0
+	newCursor := func(b *bolt.Bucket) *Cursor {
+		return NewRangeCursor(b, fromBytes, toBytes, opts)
+	}
+	count, err = bb.deleteCursorKeys(newCursor, limit)
+	return
+}
+
+/*
+DeletePrefix removes every key starting with the bytes of the provided Key in a single Update transaction and returns how many were removed. If the Key is a TupleKey, only keys starting with the same whole components are removed. Nested buckets are not removed. Expired keys with the prefix are removed along with their expiry times, but are not counted.
+*/
+func (bb *Bucketeer) DeletePrefix(prefix Key) (count int, err error) {
+	var prefixBytes []byte
+	if prefixBytes, err = encodeBound(prefix); err != nil {
+		return
+	}
+	newPrefixCursor := prefixCursorFunc(prefix)
+	newCursor := func(b *bolt.Bucket) *Cursor {
+		return newPrefixCursor(b, prefixBytes, ScanOptions{})
+	}
+	count, err = bb.deleteCursorKeys(newCursor, 0)
+	return
+}
+
+/*
+deleteCursorKeys removes the keys visited by the provided Cursor, including expired keys, in a single Update transaction and returns how many of them had not expired. If the limit is positive, it stops after that many keys which had not expired. Keys are collected before deleting them because deleting while a bolt cursor is positioned in the bucket can skip keys.
+*/
+func (bb *Bucketeer) deleteCursorKeys(newCursor func(b *bolt.Bucket) *Cursor, limit int) (count int, err error) {
+	txnFunc := func(t *BucketTx) (err error) {
+		var keys [][]byte
+		bf := func(b *bolt.Bucket) error {
+			c := newCursor(b)
+			// expired keys are visited too, so that their expiry times are removed with them
+			ttl := c.ttl
+			c.ttl = nil
+			live := 0
+			for (limit <= 0 || live < limit) && c.Next() {
+				keys = append(keys, c.ByteKey())
+				if !expiredAt(ttl, c.Key(), c.now) {
+					live++
+				}
+			}
+			return nil
+		}
+		if err = t.View(bf); err != nil {
+			return
+		}
+		count = 0
+		for _, k := range keys {
+			var existed bool
+			if existed, err = t.ForByteKey(k).deleteKey(); err != nil {
+				return
+			}
+			if existed {
+				count++
+			}
+		}
+		return
+	}
+	err = bb.Txn(txnFunc)
+	return
+}
+
+/*
+Delete removes the key and its value.
+*/
+func (s *Store[K, V]) Delete(key K) error {
+	return s.ForKey(key).Delete()
+}
+
+/*
+Exists reports whether the key exists in the Store.
+*/
+func (s *Store[K, V]) Exists(key K) (bool, error) {
+	return s.ForKey(key).Exists()
+}
+
+/*
+GetAndDelete removes the key and returns the value it had. If the key does not exist, the zero value is returned and found is false, even when the Bucketeer is in strict mode.
+*/
+func (s *Store[K, V]) GetAndDelete(key K) (value V, found bool, err error) {
+	var v []byte
+	if v, err = s.ForKey(key).GetAndDelete(); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			err = nil
+		}
+		return
+	}
+	if found = v != nil; found {
+		err = s.values.Unmarshal(v, &value)
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package bucketeer
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestDelete(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	kf := b.ForStringKey("k1")
+	kf.PutStringValue("v1")
+
+	var exists bool
+	if exists, err = kf.Exists(); err != nil || !exists {
+		t.Fatalf("Expected key to exist, got %t (%v)\n", exists, err)
+	}
+
+	var v []byte
+	if v, err = kf.GetAndDelete(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(v) != "v1" {
+		t.Fatalf("Expected v1, got %s\n", v)
+	}
+	if exists, err = kf.Exists(); err != nil || exists {
+		t.Fatalf("Expected key to not exist, got %t (%v)\n", exists, err)
+	}
+	if err = kf.Delete(); err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	for k := int64(0); k < 10; k++ {
+		b.ForInt64Key(k).PutStringValue("v")
+	}
+
+	var count int
+	if count, err = b.DeleteKeys(NewInt64Key(0), NewInt64Key(1), NewInt64Key(100)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2, got %d\n", count)
+	}
+
+	if count, err = b.DeleteRange(NewInt64Key(2), NewInt64Key(5), ScanOptions{ToExclusive: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 3 {
+		t.Fatalf("Expected 3, got %d\n", count)
+	}
+
+	var keys []int64
+	iterFunc := func(c *Cursor) error {
+		k, err := c.Int64Key()
+		keys = append(keys, int64(k))
+		return err
+	}
+	if err = b.Iterate(iterFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []int64{5, 6, 7, 8, 9}
+	if !equalInt64s(expected, keys) {
+		t.Fatalf("Expected %v, got %v\n", expected, keys)
+	}
+
+	for _, k := range []string{"a/1", "a/2", "b/1"} {
+		b.ForStringKey(k).PutStringValue("v")
+	}
+	if count, err = b.DeletePrefix(NewStringKey("a/")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2, got %d\n", count)
+	}
+}
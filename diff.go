@@ -0,0 +1,193 @@
+package bucketeer
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+DiffKind describes how a key or bucket differs between the two sides of a Diff.
+*/
+type DiffKind int
+
+const (
+	// DiffRemoved is a key or bucket which only exists on side A.
+	DiffRemoved DiffKind = iota
+	// DiffAdded is a key or bucket which only exists on side B.
+	DiffAdded
+	// DiffChanged is a key whose values are not equal, or a bucket whose sequences are not equal.
+	DiffChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffRemoved:
+		return "removed"
+	case DiffAdded:
+		return "added"
+	case DiffChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+/*
+Difference is a single difference found by Diff. Path is the path of the containing bucket relative to the compared roots, and Key is the key or nested bucket name; a changed sequence of the roots themselves has an empty Path and a nil Key. For keys, A and B hold the values from each side, or nil where the key does not exist. For buckets, Bucket is true and ASequence and BSequence hold the sequences from each side. The Path, Key and values are only valid within the scope of the DiffFunc.
+*/
+type Difference struct {
+	Kind      DiffKind
+	Path      Path
+	Key       []byte
+	Bucket    bool
+	A         []byte
+	B         []byte
+	ASequence uint64
+	BSequence uint64
+}
+
+/*
+DiffFunc is called for each Difference found by Diff, in key order. Returning ErrStopIteration ends the diff without an error.
+*/
+type DiffFunc func(d Difference) error
+
+/*
+DiffOptions controls how Diff compares buckets.
+*/
+type DiffOptions struct {
+	// Equal compares values which exist on both sides. If it is nil, values are compared bytewise.
+	Equal func(a, b []byte) bool
+	// IgnoreSequences does not report buckets whose sequences differ.
+	IgnoreSequences bool
+}
+
+/*
+JsonEqual reports whether two values hold equal JSON documents, ignoring formatting and the order of object keys. If either value is not valid JSON, the values are compared bytewise.
+*/
+func JsonEqual(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var objA, objB interface{}
+	if json.Unmarshal(a, &objA) != nil || json.Unmarshal(b, &objB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(objA, objB)
+}
+
+/*
+Diff compares the bucket at path A in database A with the bucket at path B in database B, including their nested buckets, and calls the provided function for each difference. Both trees are read with cursors in a single pass, so memory use does not depend on their size. Buckets which only exist on one side are reported once, without their contents. A key which holds a value on one side and a nested bucket on the other is reported as removed and added. Nested buckets named MetaBucketName, which hold indexes and expiry times, are not compared. If either root bucket does not exist, a BucketNotFoundError is returned.
+*/
+func Diff(dbA *bolt.DB, pathA Path, dbB *bolt.DB, pathB Path, opts DiffOptions, diffFunc DiffFunc) error {
+	txf := func(txA *bolt.Tx) error {
+		if dbA == dbB {
+			return diffTx(txA, pathA, txA, pathB, opts, diffFunc)
+		}
+		txfB := func(txB *bolt.Tx) error {
+			return diffTx(txA, pathA, txB, pathB, opts, diffFunc)
+		}
+		return dbB.View(txfB)
+	}
+	return dbA.View(txf)
+}
+
+func diffTx(txA *bolt.Tx, pathA Path, txB *bolt.Tx, pathB Path, opts DiffOptions, diffFunc DiffFunc) (err error) {
+	var a, b *bolt.Bucket
+	if a, err = RequireBucket(txA, pathA); err != nil {
+		return
+	}
+	if b, err = RequireBucket(txB, pathB); err != nil {
+		return
+	}
+	if opts.Equal == nil {
+		opts.Equal = bytes.Equal
+	}
+	if !opts.IgnoreSequences && a.Sequence() != b.Sequence() {
+		err = diffFunc(Difference{Kind: DiffChanged, Path: Path{}, Bucket: true, ASequence: a.Sequence(), BSequence: b.Sequence()})
+	}
+	if err == nil {
+		err = diffBuckets(a, b, Path{}, opts, diffFunc)
+	}
+	if err == ErrStopIteration {
+		err = nil
+	}
+	return
+}
+
+func diffBuckets(a *bolt.Bucket, b *bolt.Bucket, path Path, opts DiffOptions, diffFunc DiffFunc) (err error) {
+	only := func(kind DiffKind, bucket *bolt.Bucket, k, v []byte) error {
+		d := Difference{Kind: kind, Path: path, Key: k, Bucket: v == nil}
+		switch {
+		case v != nil && kind == DiffRemoved:
+			d.A = v
+		case v != nil:
+			d.B = v
+		case kind == DiffRemoved:
+			d.ASequence = bucket.Bucket(k).Sequence()
+		default:
+			d.BSequence = bucket.Bucket(k).Sequence()
+		}
+		return diffFunc(d)
+	}
+
+	// the nested buckets named MetaBucketName hold derived data, so they are not compared
+	skipMeta := func(c *bolt.Cursor, k, v []byte) ([]byte, []byte) {
+		if k != nil && v == nil && bytes.Equal(k, metaBucketName) {
+			return c.Next()
+		}
+		return k, v
+	}
+	ca, cb := a.Cursor(), b.Cursor()
+	ka, va := ca.First()
+	ka, va = skipMeta(ca, ka, va)
+	kb, vb := cb.First()
+	kb, vb = skipMeta(cb, kb, vb)
+	for ka != nil || kb != nil {
+		cmp := 0
+		if ka == nil {
+			cmp = 1
+		} else if kb != nil {
+			cmp = bytes.Compare(ka, kb)
+		} else {
+			cmp = -1
+		}
+
+		switch {
+		case cmp < 0:
+			err = only(DiffRemoved, a, ka, va)
+		case cmp > 0:
+			err = only(DiffAdded, b, kb, vb)
+		case va != nil && vb != nil:
+			if !opts.Equal(va, vb) {
+				err = diffFunc(Difference{Kind: DiffChanged, Path: path, Key: ka, A: va, B: vb})
+			}
+		case va == nil && vb == nil:
+			nestedA, nestedB := a.Bucket(ka), b.Bucket(kb)
+			if !opts.IgnoreSequences && nestedA.Sequence() != nestedB.Sequence() {
+				err = diffFunc(Difference{Kind: DiffChanged, Path: path, Key: ka, Bucket: true, ASequence: nestedA.Sequence(), BSequence: nestedB.Sequence()})
+			}
+			if err == nil {
+				err = diffBuckets(nestedA, nestedB, nestPath(path, ka), opts, diffFunc)
+			}
+		default:
+			if err = only(DiffRemoved, a, ka, va); err == nil {
+				err = only(DiffAdded, b, kb, vb)
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		if cmp <= 0 {
+			ka, va = ca.Next()
+			ka, va = skipMeta(ca, ka, va)
+		}
+		if cmp >= 0 {
+			kb, vb = cb.Next()
+			kb, vb = skipMeta(cb, kb, vb)
+		}
+	}
+	return
+}
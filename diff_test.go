@@ -0,0 +1,122 @@
+package bucketeer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestDiff(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	a := New(db, "a")
+	a.EnsurePathBuckets()
+	a.EnsureNestedBucket("nested")
+	a.EnsureNestedBucket("old")
+	a.ForStringKey("same").PutStringValue("v")
+	a.ForStringKey("changed").PutStringValue("v1")
+	a.ForStringKey("json").PutByteValue([]byte(`{"a":1,"b":2}`))
+	a.ForStringKey("removed").PutStringValue("v")
+	a.ForStringKey("kind").PutStringValue("v")
+	a.InNestedBucket("nested").ForStringKey("k").PutStringValue("v1")
+
+	b := New(db, "b")
+	b.EnsurePathBuckets()
+	b.EnsureNestedBucket("nested")
+	b.EnsureNestedBucket("kind")
+	b.ForStringKey("same").PutStringValue("v")
+	b.ForStringKey("changed").PutStringValue("v2")
+	b.ForStringKey("json").PutByteValue([]byte(`{"b": 2, "a": 1}`))
+	b.ForStringKey("added").PutStringValue("v")
+	b.InNestedBucket("nested").ForStringKey("k").PutStringValue("v2")
+	b.UpdateWithSequence(func(b *bolt.Bucket, sequence uint64) error { return nil })
+
+	var diffs []string
+	diffFunc := func(d Difference) error {
+		diffs = append(diffs, d.Kind.String()+" "+d.Path.Nest(string(d.Key)).String())
+		return nil
+	}
+
+	if err = Diff(db, NewPath("a"), db, NewPath("b"), DiffOptions{Equal: JsonEqual}, diffFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []string{
+		"changed []",
+		"added [added]",
+		"changed [changed]",
+		"removed [kind]",
+		"added [kind]",
+		"changed [nested, k]",
+		"removed [old]",
+		"removed [removed]",
+	}
+	if !reflect.DeepEqual(expected, diffs) {
+		t.Fatalf("Expected %v, got %v\n", expected, diffs)
+	}
+
+	diffs = nil
+	if err = Diff(db, NewPath("a"), db, NewPath("b"), DiffOptions{IgnoreSequences: true}, diffFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if diffs[0] != "added [added]" || diffs[2] != "changed [json]" {
+		t.Fatalf("Expected bytewise comparison without sequences, got %v\n", diffs)
+	}
+
+	diffs = nil
+	stopFunc := func(d Difference) error {
+		diffs = append(diffs, d.Kind.String())
+		return ErrStopIteration
+	}
+	if err = Diff(db, NewPath("a"), db, NewPath("b"), DiffOptions{}, stopFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("Expected one difference before stopping, got %v\n", diffs)
+	}
+
+	diffs = nil
+	if err = Diff(db, NewPath("a"), db, NewPath("a"), DiffOptions{}, stopFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(diffs) != 0 {
+		t.Fatalf("Expected no differences, got %v\n", diffs)
+	}
+}
+
+func TestDiffSkipsMetaBucket(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	a := New(db, "a")
+	a.EnsurePathBuckets()
+	a.ForStringKey("k").PutWithTTL([]byte("v"), time.Hour)
+	b := New(db, "b")
+	b.EnsurePathBuckets()
+	b.ForStringKey("k").PutStringValue("v")
+	byValue := func(v *string) (Key, bool) {
+		return NewStringKey(*v), true
+	}
+	if err = AddIndex(b, "value", RawCodec, byValue); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer b.RemoveIndex("value")
+
+	diffFunc := func(d Difference) error {
+		t.Fatalf("Expected no differences, got %s %s %s\n", d.Kind, d.Path.String(), d.Key)
+		return nil
+	}
+	if err = Diff(db, NewPath("a"), db, NewPath("b"), DiffOptions{}, diffFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,118 @@
+package bucketeer
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBucketNotFound is matched by errors.Is for any BucketNotFoundError.
+	ErrBucketNotFound = errors.New("Bucket not found")
+	// ErrKeyNotFound is matched by errors.Is for any KeyNotFoundError.
+	ErrKeyNotFound = errors.New("Key not found")
+	// ErrConditionFailed is matched by errors.Is for any ConditionFailedError.
+	ErrConditionFailed = errors.New("Condition failed")
+	// ErrOverflow is returned when incrementing a value would overflow its integer type.
+	ErrOverflow = errors.New("Integer overflow")
+	// ErrMergeConflict is matched by errors.Is for any MergeConflictError.
+	ErrMergeConflict = errors.New("Merge conflict")
+	// ErrUniqueViolation is matched by errors.Is for any UniqueViolationError.
+	ErrUniqueViolation = errors.New("Unique index violation")
+)
+
+/*
+BucketNotFoundError is returned when one or more buckets along a path do not exist.
+*/
+type BucketNotFoundError struct {
+	Path Path
+}
+
+func (e *BucketNotFoundError) Error() string {
+	return fmt.Sprintf("Did not find one or more path buckets: %s", e.Path.String())
+}
+
+/*
+Is reports whether the target is ErrBucketNotFound.
+*/
+func (e *BucketNotFoundError) Is(target error) bool {
+	return target == ErrBucketNotFound
+}
+
+/*
+KeyNotFoundError is returned by a strict Bucketeer when a key does not exist in its bucket.
+*/
+type KeyNotFoundError struct {
+	Path Path
+	Key  []byte
+}
+
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("Did not find key %q in bucket %s", e.Key, e.Path.String())
+}
+
+/*
+Is reports whether the target is ErrKeyNotFound.
+*/
+func (e *KeyNotFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+/*
+ConditionFailedError is returned when a conditional write is not made because the key's current value did not match the expected value. Current is a copy of the current value, or nil if the key does not exist.
+*/
+type ConditionFailedError struct {
+	Path    Path
+	Key     []byte
+	Current []byte
+}
+
+func (e *ConditionFailedError) Error() string {
+	return fmt.Sprintf("Condition failed for key %q in bucket %s", e.Key, e.Path.String())
+}
+
+/*
+Is reports whether the target is ErrConditionFailed.
+*/
+func (e *ConditionFailedError) Is(target error) bool {
+	return target == ErrConditionFailed
+}
+
+/*
+MergeConflictError is returned by MergeFromFile with the ConflictFail policy when a key from the file already exists in the destination with a different value, or a key is a value on one side and a nested bucket on the other.
+*/
+type MergeConflictError struct {
+	Path Path
+	Key  []byte
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("Merge conflict for key %q in bucket %s", e.Key, e.Path.String())
+}
+
+/*
+Is reports whether the target is ErrMergeConflict.
+*/
+func (e *MergeConflictError) Is(target error) bool {
+	return target == ErrMergeConflict
+}
+
+/*
+UniqueViolationError is returned when a write would give a key the same unique index key as another key in the bucket. The write is not made, even if the error is handled within a BucketTx and the transaction commits.
+*/
+type UniqueViolationError struct {
+	Path     Path
+	Index    string
+	Key      []byte
+	Conflict []byte
+}
+
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("Key %q in bucket %s has the same value for unique index %q as key %q", e.Key, e.Path.String(), e.Index, e.Conflict)
+}
+
+/*
+Is reports whether the target is ErrUniqueViolation.
+*/
+func (e *UniqueViolationError) Is(target error) bool {
+	return target == ErrUniqueViolation
+}
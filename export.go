@@ -0,0 +1,322 @@
+package bucketeer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ExportOptions controls how keys, bucket names and values are written by Export. By default they are all written in base64, which is always lossless. The text and JSON forms are only used for bytes which can be restored exactly by Import, so every option is safe to use with any data.
+*/
+type ExportOptions struct {
+	// TextKeys writes keys and bucket names which are valid UTF-8 as JSON strings.
+	TextKeys bool
+	// TextValues writes values which are valid UTF-8 as JSON strings.
+	TextValues bool
+	// JsonValues writes values which are compact JSON documents as embedded JSON. It takes precedence over TextValues.
+	JsonValues bool
+}
+
+/*
+exportRecord is a single line of an export. A bucket record has Bucket set and carries the bucket's sequence; a key record has Key and Value set. Path is relative to the root of the export.
+*/
+type exportRecord struct {
+	Path     []exportBytes `json:"path"`
+	Bucket   bool          `json:"bucket,omitempty"`
+	Sequence uint64        `json:"sequence,omitempty"`
+	Key      *exportBytes  `json:"key,omitempty"`
+	Value    *exportBytes  `json:"value,omitempty"`
+}
+
+const (
+	exportBase64 = iota
+	exportText
+	exportJson
+)
+
+/*
+exportBytes is written as a JSON string for text, {"json": ...} for embedded JSON, or {"base64": "..."} for anything else.
+*/
+type exportBytes struct {
+	b    []byte
+	form int
+}
+
+func (eb exportBytes) MarshalJSON() ([]byte, error) {
+	switch eb.form {
+	case exportText:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(string(eb.b)); err != nil {
+			return nil, err
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+	case exportJson:
+		// written verbatim, because encoding/json would escape characters in the embedded document and change its bytes
+		b := append([]byte(`{"json":`), eb.b...)
+		return append(b, '}'), nil
+	}
+	return json.Marshal(struct {
+		Base64 []byte `json:"base64"`
+	}{eb.b})
+}
+
+func (eb *exportBytes) UnmarshalJSON(data []byte) (err error) {
+	if len(data) != 0 && data[0] == '"' {
+		var s string
+		if err = json.Unmarshal(data, &s); err != nil {
+			return
+		}
+		eb.b, eb.form = []byte(s), exportText
+		return
+	}
+	var obj struct {
+		Base64 []byte          `json:"base64"`
+		Json   json.RawMessage `json:"json"`
+	}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return
+	}
+	switch {
+	case obj.Json != nil:
+		var buf bytes.Buffer
+		if err = json.Compact(&buf, obj.Json); err != nil {
+			return
+		}
+		eb.b, eb.form = buf.Bytes(), exportJson
+	case obj.Base64 != nil:
+		eb.b, eb.form = obj.Base64, exportBase64
+	default:
+		err = errors.New("Expected a string, base64 or json object")
+	}
+	return
+}
+
+/*
+appendLine appends the record as a line of JSON. The record is written by hand rather than with encoding/json, which would compact and escape the bytes of embedded JSON values.
+*/
+func (r exportRecord) appendLine(line []byte) (_ []byte, err error) {
+	line = append(line, `{"path":[`...)
+	for i, name := range r.Path {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		if line, err = appendExportBytes(line, name); err != nil {
+			return
+		}
+	}
+	line = append(line, ']')
+	if r.Bucket {
+		line = append(line, `,"bucket":true`...)
+	}
+	if r.Sequence != 0 {
+		line = append(line, `,"sequence":`...)
+		line = strconv.AppendUint(line, r.Sequence, 10)
+	}
+	if r.Key != nil {
+		line = append(line, `,"key":`...)
+		if line, err = appendExportBytes(line, *r.Key); err != nil {
+			return
+		}
+	}
+	if r.Value != nil {
+		line = append(line, `,"value":`...)
+		if line, err = appendExportBytes(line, *r.Value); err != nil {
+			return
+		}
+	}
+	return append(line, '}', '\n'), nil
+}
+
+func appendExportBytes(line []byte, eb exportBytes) ([]byte, error) {
+	b, err := eb.MarshalJSON()
+	return append(line, b...), err
+}
+
+func (opts ExportOptions) keyBytes(k []byte) exportBytes {
+	if opts.TextKeys && utf8.Valid(k) {
+		return exportBytes{k, exportText}
+	}
+	return exportBytes{k, exportBase64}
+}
+
+func (opts ExportOptions) valueBytes(v []byte) exportBytes {
+	if opts.JsonValues && isCompactJson(v) {
+		return exportBytes{v, exportJson}
+	}
+	if opts.TextValues && utf8.Valid(v) {
+		return exportBytes{v, exportText}
+	}
+	return exportBytes{v, exportBase64}
+}
+
+func isCompactJson(v []byte) bool {
+	if !json.Valid(v) {
+		return false
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, v) != nil {
+		return false
+	}
+	return bytes.Equal(buf.Bytes(), v)
+}
+
+/*
+Export writes the bucket at the provided path and everything nested beneath it as JSON Lines, in a single View transaction. Each bucket is written as a record with its sequence, followed by its keys and nested buckets in key order. Nested buckets named MetaBucketName are not exported, so indexes and expiry times are not restored by Import. Paths in the output are relative to the exported bucket, so the output can be imported under any path. If the path is empty, every top-level bucket in the database is exported.
+*/
+func Export(db *bolt.DB, path Path, w io.Writer, opts ExportOptions) error {
+	txf := func(tx *bolt.Tx) error {
+		return exportTx(tx, path, w, opts)
+	}
+	return db.View(txf)
+}
+
+/*
+Export writes the current bucket and everything nested beneath it as JSON Lines. See the Export function for the format.
+*/
+func (bb *Bucketeer) Export(w io.Writer, opts ExportOptions) error {
+	txf := func(tx *bolt.Tx) error {
+		return exportTx(tx, bb.path, w, opts)
+	}
+	return bb.viewTx(txf)
+}
+
+func exportTx(tx *bolt.Tx, path Path, w io.Writer, opts ExportOptions) (err error) {
+	bw := bufio.NewWriter(w)
+	if len(path) == 0 {
+		c := tx.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if err = exportBucket(bw, tx.Bucket(k), nestPath(nil, k), opts); err != nil {
+				return
+			}
+		}
+	} else {
+		var b *bolt.Bucket
+		if b, err = RequireBucket(tx, path); err != nil {
+			return
+		}
+		if err = exportBucket(bw, b, Path{}, opts); err != nil {
+			return
+		}
+	}
+	err = bw.Flush()
+	return
+}
+
+func exportBucket(bw *bufio.Writer, b *bolt.Bucket, path Path, opts ExportOptions) (err error) {
+	names := make([]exportBytes, len(path))
+	for i, name := range path {
+		names[i] = opts.keyBytes(name)
+	}
+	var line []byte
+	if line, err = (exportRecord{Path: names, Bucket: true, Sequence: b.Sequence()}).appendLine(line); err != nil {
+		return
+	}
+	if _, err = bw.Write(line); err != nil {
+		return
+	}
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			if bytes.Equal(k, metaBucketName) {
+				continue
+			}
+			if err = exportBucket(bw, b.Bucket(k), nestPath(path, k), opts); err != nil {
+				return
+			}
+			continue
+		}
+		key, value := opts.keyBytes(k), opts.valueBytes(v)
+		if line, err = (exportRecord{Path: names, Key: &key, Value: &value}).appendLine(line[:0]); err != nil {
+			return
+		}
+		if _, err = bw.Write(line); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/*
+Import reads JSON Lines written by Export and restores them under the provided path in a single Update transaction. Buckets are created as needed, bucket sequences are restored, and existing keys are overwritten. If any record cannot be read, nothing is imported.
+*/
+func Import(db *bolt.DB, path Path, r io.Reader) error {
+	txf := func(tx *bolt.Tx) error {
+		return importTx(tx, path, r)
+	}
+	return db.Update(txf)
+}
+
+/*
+Import reads JSON Lines written by Export and restores them under the current bucket. See the Import function for details.
+*/
+func (bb *Bucketeer) Import(r io.Reader) error {
+	txf := func(tx *bolt.Tx) error {
+		return importTx(tx, bb.path, r)
+	}
+	return bb.updateTx(txf)
+}
+
+func importTx(tx *bolt.Tx, root Path, r io.Reader) (err error) {
+	dec := json.NewDecoder(r)
+	var b *bolt.Bucket
+	var bPath Path
+	for line := 1; ; line++ {
+		var rec exportRecord
+		if err = dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("Import record %d: %s", line, err.Error())
+		}
+		path := make(Path, 0, len(root)+len(rec.Path))
+		path = append(path, root...)
+		for _, name := range rec.Path {
+			path = append(path, name.b)
+		}
+		if len(path) == 0 {
+			if !rec.Bucket {
+				return fmt.Errorf("Import record %d: keys cannot be imported at the top level", line)
+			}
+			continue
+		}
+		if b == nil || !pathEqual(path, bPath) {
+			if b, err = ensureBucket(tx, path); err != nil {
+				return
+			}
+			bPath = path
+		}
+		if rec.Bucket {
+			if err = b.SetSequence(rec.Sequence); err != nil {
+				return
+			}
+			continue
+		}
+		if rec.Key == nil || rec.Value == nil {
+			return fmt.Errorf("Import record %d: expected a bucket or a key and value", line)
+		}
+		if err = b.Put(rec.Key.b, rec.Value.b); err != nil {
+			return
+		}
+	}
+}
+
+func pathEqual(a, b Path) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
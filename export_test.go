@@ -0,0 +1,118 @@
+package bucketeer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestExportImport(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	src := New(db, "src")
+	src.EnsurePathBuckets()
+	src.EnsureNestedBucket("nested")
+	src.UpdateWithSequence(func(b *bolt.Bucket, sequence uint64) error { return nil })
+	src.ForStringKey("json").PutJsonValue(map[string]int{"a": 1})
+	src.ForStringKey("text").PutStringValue("hello")
+	src.ForStringKey("empty").PutByteValue([]byte{})
+	src.ForUint64Key(1).PutByteValue([]byte{0xff, 0x00})
+	src.InNestedBucket("nested").ForStringKey("k").PutStringValue("v")
+
+	var buf bytes.Buffer
+	if err = src.Export(&buf, ExportOptions{TextKeys: true, TextValues: true, JsonValues: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected 7 lines, got %d: %s\n", len(lines), buf.String())
+	}
+	expected := `{"path":[],"bucket":true,"sequence":1}`
+	if lines[0] != expected {
+		t.Fatalf("Expected %s, got %s\n", expected, lines[0])
+	}
+	expected = `{"path":[],"key":"json","value":{"json":{"a":1}}}`
+	if lines[3] != expected {
+		t.Fatalf("Expected %s, got %s\n", expected, lines[3])
+	}
+
+	dst := New(db, "dst", "copy")
+	if err = dst.Import(&buf); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var a, b bytes.Buffer
+	src.Export(&a, ExportOptions{})
+	dst.Export(&b, ExportOptions{})
+	if a.String() != b.String() {
+		t.Fatalf("Expected %s, got %s\n", a.String(), b.String())
+	}
+
+	var v []byte
+	if v, err = dst.ForStringKey("empty").GetByteValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v == nil || len(v) != 0 {
+		t.Fatalf("Expected an empty value, got %v\n", v)
+	}
+	if _, err = dst.UpdateWithSequence(func(b *bolt.Bucket, sequence uint64) error {
+		if sequence != 2 {
+			t.Fatalf("Expected sequence 2, got %d\n", sequence)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = Import(db, Path{}, strings.NewReader(`{"path":[],"key":"k","value":"v"}`))
+	if err == nil {
+		t.Fatal("Expected an error for a top-level key")
+	}
+	err = Import(db, NewPath("partial"), strings.NewReader(`{"path":[],"key":"k","value":"v"}`+"\n"+`{"path":[`))
+	if err == nil {
+		t.Fatal("Expected an error for a truncated record")
+	}
+	if v, _ = New(db, "partial").ForStringKey("k").GetByteValue(); v != nil {
+		t.Fatalf("Expected no value after a failed import, got %s\n", v)
+	}
+}
+
+func TestExportImportEscapes(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	values := map[string]string{
+		"json": "{\"x\":\"<b>& \"}",
+		"text": "<b>& ",
+	}
+	src := New(db, "src")
+	src.EnsurePathBuckets()
+	for k, v := range values {
+		src.ForStringKey(k).PutStringValue(v)
+	}
+
+	var buf bytes.Buffer
+	if err = src.Export(&buf, ExportOptions{TextKeys: true, TextValues: true, JsonValues: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	dst := New(db, "dst")
+	if err = dst.Import(&buf); err != nil {
+		t.Fatal(err.Error())
+	}
+	for k, expected := range values {
+		if v, _ := dst.ForStringKey(k).GetStringValue(); v != expected {
+			t.Fatalf("Expected %q for %s, got %q\n", expected, k, v)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package bucketeer
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ConflictPolicy determines what MergeFromFile does when a key from the file already exists in the destination with a different value.
+*/
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite replaces the destination's value or nested bucket with the one from the file.
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictSkip keeps the destination's value or nested bucket.
+	ConflictSkip
+	// ConflictFail aborts the merge with a MergeConflictError, and nothing is merged.
+	ConflictFail
+)
+
+/*
+ExtractToFile creates a new bolt database file containing only the bucket at the provided path and everything beneath it, with its parent buckets recreated so the bucket has the same path in the new file. The file is written to a temporary file in the same directory and renamed once it is complete, replacing any existing file with that name.
+*/
+func ExtractToFile(db *bolt.DB, path Path, filename string) (err error) {
+	if len(path) == 0 {
+		panic("Path must have at least one element")
+	}
+	writeFunc := func(tmpName string) (err error) {
+		var dstDB *bolt.DB
+		if dstDB, err = bolt.Open(tmpName, 0600, &bolt.Options{Timeout: time.Second}); err != nil {
+			return
+		}
+		err = CopyBucket(db, path, dstDB, path)
+		if closeErr := dstDB.Close(); err == nil {
+			err = closeErr
+		}
+		return
+	}
+	err = replaceFile(filename, writeFunc)
+	return
+}
+
+/*
+MergeFromFile merges every bucket in a bolt database file into the provided path, in a single Update transaction. The file's top-level buckets become nested buckets of the path, so a file written by ExtractToFile is restored to its original location by merging it into an empty path. Buckets are created as needed, and bucket sequences are set to the greater of the two so that NextSequence does not repeat values. Keys which already exist with the same value are not conflicts; other conflicts are resolved by the provided policy.
+
+Merged keys are written directly to the destination buckets, so indexes registered with AddIndex are not updated and watchers registered with Watch are not notified; use RebuildIndex after merging into a path with indexes.
+*/
+func MergeFromFile(db *bolt.DB, path Path, filename string, policy ConflictPolicy) (err error) {
+	if _, err = os.Stat(filename); err != nil {
+		return
+	}
+	var srcDB *bolt.DB
+	if srcDB, err = bolt.Open(filename, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true}); err != nil {
+		return
+	}
+	defer srcDB.Close()
+
+	txf := func(srcTx *bolt.Tx) error {
+		dstTxf := func(dstTx *bolt.Tx) (err error) {
+			var dst *bolt.Bucket
+			if len(path) != 0 {
+				if dst, err = ensureBucket(dstTx, path); err != nil {
+					return
+				}
+			}
+			c := srcTx.Cursor()
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				var nested *bolt.Bucket
+				if dst == nil {
+					nested, err = dstTx.CreateBucketIfNotExists(k)
+				} else {
+					nested, err = mergeNestedBucket(dst, k, path, policy)
+				}
+				if err != nil {
+					return
+				}
+				if nested == nil {
+					continue
+				}
+				if err = mergeBucket(srcTx.Bucket(k), nested, nestPath(path, k), policy); err != nil {
+					return
+				}
+			}
+			return
+		}
+		return db.Update(dstTxf)
+	}
+	err = srcDB.View(txf)
+	return
+}
+
+/*
+mergeNestedBucket returns the destination's nested bucket for a bucket being merged, creating it if needed. If the destination has a value with that name, the policy is applied; a nil bucket is returned if the bucket should be skipped.
+*/
+func mergeNestedBucket(dst *bolt.Bucket, name []byte, path Path, policy ConflictPolicy) (nested *bolt.Bucket, err error) {
+	if dst.Get(name) != nil {
+		switch policy {
+		case ConflictSkip:
+			return
+		case ConflictFail:
+			err = mergeConflict(path, name)
+			return
+		}
+		if err = dst.Delete(name); err != nil {
+			return
+		}
+	}
+	nested, err = dst.CreateBucketIfNotExists(name)
+	return
+}
+
+func mergeBucket(src *bolt.Bucket, dst *bolt.Bucket, path Path, policy ConflictPolicy) (err error) {
+	if src.Sequence() > dst.Sequence() {
+		if err = dst.SetSequence(src.Sequence()); err != nil {
+			return
+		}
+	}
+	c := src.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			var nested *bolt.Bucket
+			if nested, err = mergeNestedBucket(dst, k, path, policy); err != nil {
+				return
+			}
+			if nested != nil {
+				if err = mergeBucket(src.Bucket(k), nested, nestPath(path, k), policy); err != nil {
+					return
+				}
+			}
+			continue
+		}
+
+		existingBucket := dst.Bucket(k) != nil
+		if existing := dst.Get(k); existingBucket || existing != nil {
+			if !existingBucket && bytes.Equal(existing, v) {
+				continue
+			}
+			switch policy {
+			case ConflictSkip:
+				continue
+			case ConflictFail:
+				err = mergeConflict(path, k)
+				return
+			}
+			if existingBucket {
+				if err = dst.DeleteBucket(k); err != nil {
+					return
+				}
+			}
+		}
+		if err = dst.Put(k, v); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/*
+mergeConflict copies the key, because it refers to the memory of the source file's transaction.
+*/
+func mergeConflict(path Path, key []byte) error {
+	return &MergeConflictError{Path: path, Key: append([]byte(nil), key...)}
+}
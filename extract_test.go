@@ -0,0 +1,80 @@
+package bucketeer
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestExtractMerge(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	acme := New(db, "tenants", "acme")
+	acme.EnsurePathBuckets()
+	acme.EnsureNestedBucket("users")
+	acme.ForStringKey("name").PutStringValue("Acme")
+	acme.ForStringKey("plan").PutStringValue("gold")
+	acme.InNestedBucket("users").ForStringKey("u1").PutStringValue("alice")
+	New(db, "tenants", "other").EnsurePathBuckets()
+
+	filename := tempfile()
+	defer os.Remove(filename)
+	if err = ExtractToFile(db, NewPath("tenants", "acme"), filename); err != nil {
+		t.Fatal(err.Error())
+	}
+	var buckets int
+	if buckets, err = VerifyFile(filename); err != nil {
+		t.Fatal(err.Error())
+	}
+	if buckets != 3 {
+		t.Fatalf("Expected 3 buckets in the extracted file, got %d\n", buckets)
+	}
+
+	if err = MergeFromFile(db, NewPath("archive"), filename, ConflictFail); err != nil {
+		t.Fatal(err.Error())
+	}
+	var v string
+	if v, err = New(db, "archive", "tenants", "acme", "users").ForStringKey("u1").GetStringValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != "alice" {
+		t.Fatalf("Expected alice, got %s\n", v)
+	}
+
+	// identical values are not conflicts
+	if err = MergeFromFile(db, Path{}, filename, ConflictFail); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	acme.ForStringKey("plan").PutStringValue("silver")
+	acme.InNestedBucket("users").ForStringKey("u2").PutStringValue("bob")
+	err = MergeFromFile(db, Path{}, filename, ConflictFail)
+	var conflict *MergeConflictError
+	if !errors.As(err, &conflict) || string(conflict.Key) != "plan" {
+		t.Fatalf("Expected a conflict for plan, got %v\n", err)
+	}
+
+	if err = MergeFromFile(db, Path{}, filename, ConflictSkip); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ = acme.ForStringKey("plan").GetStringValue(); v != "silver" {
+		t.Fatalf("Expected silver, got %s\n", v)
+	}
+
+	if err = MergeFromFile(db, Path{}, filename, ConflictOverwrite); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ = acme.ForStringKey("plan").GetStringValue(); v != "gold" {
+		t.Fatalf("Expected gold, got %s\n", v)
+	}
+	if v, _ = acme.InNestedBucket("users").ForStringKey("u2").GetStringValue(); v != "bob" {
+		t.Fatalf("Expected keys missing from the file to be kept, got %q\n", v)
+	}
+}
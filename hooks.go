@@ -0,0 +1,176 @@
+package bucketeer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+MetaBucketName is the name of the nested bucket which holds the data this package maintains alongside a bucket's values, such as indexes and expiry times. It must not be used as a key. Cursors skip nested buckets, and Walk, GetBucketStats and Export skip this one, so it does not appear in iteration.
+*/
+const MetaBucketName = "_bucketeer"
+
+var metaBucketName = []byte(MetaBucketName)
+
+/*
+pathHooks holds the indexes which must be updated, and the watchers which must be notified, when a key in a bucket is written through a Keyfarer.
+*/
+type pathHooks struct {
+	indexes  []*index
+	watchers []*watcher
+}
+
+func (h *pathHooks) empty() bool {
+	return len(h.indexes) == 0 && len(h.watchers) == 0
+}
+
+/*
+registry holds the hooks for each database and bucket path. Hooks are registered for the life of the process, so every Bucketeer for the same database and path shares them, regardless of how it was created.
+*/
+var registry = struct {
+	sync.RWMutex
+	hooks map[*bolt.DB]map[string]*pathHooks
+}{
+	hooks: make(map[*bolt.DB]map[string]*pathHooks),
+}
+
+/*
+pathKey encodes a Path as a map key, with each bucket name prefixed by its length so that different paths cannot collide.
+*/
+func pathKey(p Path) string {
+	var b []byte
+	for _, name := range p {
+		b = binary.AppendUvarint(b, uint64(len(name)))
+		b = append(b, name...)
+	}
+	return string(b)
+}
+
+/*
+lookupHooks returns a snapshot of the hooks for the provided database and path, or nil if there are none.
+*/
+func lookupHooks(db *bolt.DB, path Path) *pathHooks {
+	registry.RLock()
+	defer registry.RUnlock()
+	if h := registry.hooks[db][pathKey(path)]; h != nil {
+		snapshot := *h
+		return &snapshot
+	}
+	return nil
+}
+
+/*
+modifyHooks calls the provided function with the hooks for the provided database and path while holding the registry lock. The function must replace any slices it changes rather than modifying them, because snapshots share them.
+*/
+func modifyHooks(db *bolt.DB, path Path, modifyFunc func(h *pathHooks)) {
+	registry.Lock()
+	defer registry.Unlock()
+	paths := registry.hooks[db]
+	if paths == nil {
+		paths = make(map[string]*pathHooks)
+		registry.hooks[db] = paths
+	}
+	key := pathKey(path)
+	h := paths[key]
+	if h == nil {
+		h = &pathHooks{}
+	}
+	modifyFunc(h)
+	if h.empty() {
+		delete(paths, key)
+		if len(paths) == 0 {
+			delete(registry.hooks, db)
+		}
+		return
+	}
+	paths[key] = h
+}
+
+/*
+writeFunc wraps a function which writes the provided key, so that the bucket's indexes are updated in the same transaction and its watchers are notified when the transaction commits. An expired value is deleted before the function sees it, and the key's expiry is removed if the function changes the value without setting a new expiry. If the new value violates a unique index, the key's value and expiry are restored before the UniqueViolationError is returned, so the write is not made even if the transaction goes on to commit.
+*/
+func (bb *Bucketeer) writeFunc(key []byte, updateFunc func(b *bolt.Bucket) error) func(b *bolt.Bucket) error {
+	h := lookupHooks(bb.db, bb.path)
+	return func(b *bolt.Bucket) (err error) {
+		ttl := ttlBucket(b)
+		if h == nil && ttl == nil {
+			return updateFunc(b)
+		}
+		var oldValue, oldExpiry, liveExpiry []byte
+		if v := b.Get(key); v != nil {
+			oldValue = append([]byte{}, v...)
+		}
+		// an expired value is absent for watchers, but its index entries must still be replaced
+		visibleValue := oldValue
+		if ttl != nil {
+			if v := ttl.Get(key); v != nil {
+				oldExpiry = append([]byte{}, v...)
+			}
+			if isExpired(b, key) {
+				visibleValue = nil
+			} else {
+				liveExpiry = oldExpiry
+			}
+			if err = expireKey(b, key); err != nil {
+				return
+			}
+		}
+		if err = updateFunc(b); err != nil {
+			return
+		}
+		newValue := b.Get(key)
+		if liveExpiry != nil && bytes.Equal(liveExpiry, ttlBucket(b).Get(key)) && (newValue == nil || !bytes.Equal(oldValue, newValue)) {
+			if err = clearExpiry(b, key); err != nil {
+				return
+			}
+		}
+		if h == nil {
+			return
+		}
+		for _, idx := range h.indexes {
+			if err = idx.check(b, bb.path, key, newValue); err != nil {
+				if restoreErr := restoreKey(b, key, oldValue, oldExpiry); restoreErr != nil {
+					err = restoreErr
+				}
+				return
+			}
+		}
+		for _, idx := range h.indexes {
+			if err = idx.update(b, key, oldValue, newValue); err != nil {
+				return
+			}
+		}
+		changed := (visibleValue == nil) != (newValue == nil) || !bytes.Equal(visibleValue, newValue)
+		// removing an expired value is reported as a delete, so watchers see the key expire
+		if changed || (oldValue != nil && newValue == nil) {
+			notifyWatchers(b.Tx(), h.watchers, bb.path, key, newValue)
+		}
+		return
+	}
+}
+
+/*
+restoreKey puts back the value and expiry a key had before a write.
+*/
+func restoreKey(b *bolt.Bucket, key []byte, value []byte, expiry []byte) (err error) {
+	if value == nil {
+		err = b.Delete(key)
+	} else {
+		err = b.Put(key, value)
+	}
+	if err != nil {
+		return
+	}
+	if err = clearExpiry(b, key); err != nil || expiry == nil {
+		return
+	}
+	var k Int64Key
+	if err = k.DecodeKey(expiry); err != nil {
+		return
+	}
+	err = setExpiry(b, key, int64(k))
+	return
+}
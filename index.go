@@ -0,0 +1,416 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ErrIndexNotFound is returned when querying an index which has not been built in the bucket.
+*/
+var ErrIndexNotFound = errors.New("Index not found")
+
+var indexBucketName = []byte("index")
+
+/*
+index maintains a nested bucket of entries mapping index keys to the keys of the values they were extracted from. Each entry's key is a TupleKey of the index key and the value's key, so entries for the same index key are adjacent and ordered by the value's key.
+*/
+type index struct {
+	name   []byte
+	unique bool
+	// indexKey extracts the encoded index key from a value; ok is false if the value should not be indexed
+	indexKey func(value []byte) (key []byte, ok bool, err error)
+}
+
+/*
+AddIndex registers a secondary index on the bucket. The index function receives each value decoded with the provided Codec and returns its index key, or false if the value should not be indexed. Values which cannot be decoded are not indexed. If the Codec is nil, the Bucketeer's default Codec is used.
+
+From then on, every write made through a Keyfarer for this database and path, including writes in a BucketTx, updates the index in the same transaction. Writes made directly to a bolt.Bucket, or by Import, CopyBucket and MergeFromFile, are not indexed; use RebuildIndex after making them. The index is kept in the nested bucket named MetaBucketName, so that name must not be used for keys. Each time the index is registered, it is rebuilt from the bucket's existing values, so entries left by an earlier registration, a previous process, or writes made while it was not registered are replaced.
+
+Indexes are registered for the life of the process and apply to every Bucketeer for the same database and path. Registering an index with the same name again replaces it, including its entries.
+*/
+func AddIndex[V any](bb *Bucketeer, name string, codec Codec, indexFunc func(value *V) (Key, bool)) error {
+	if codec == nil {
+		codec = bb.Codec()
+	}
+	unmarshal := func(b []byte, value *V) error {
+		return codec.Unmarshal(b, value)
+	}
+	return bb.addIndex(newIndex(name, unmarshal, indexFunc))
+}
+
+/*
+AddUniqueIndex registers a secondary index on the bucket as AddIndex does, and also enforces that no two keys have the same index key. A write which would violate the constraint fails with a UniqueViolationError, checked in the same transaction as the write. The existing values are always checked when the index is registered, even if an index with the same name was built before. If they violate the constraint, a UniqueViolationError is returned and the index is not registered; any earlier registration with the same name and its entries are left as they were.
+*/
+func AddUniqueIndex[V any](bb *Bucketeer, name string, codec Codec, indexFunc func(value *V) (Key, bool)) error {
+	if codec == nil {
+		codec = bb.Codec()
+	}
+	unmarshal := func(b []byte, value *V) error {
+		return codec.Unmarshal(b, value)
+	}
+	idx := newIndex(name, unmarshal, indexFunc)
+	idx.unique = true
+	return bb.addIndex(idx)
+}
+
+/*
+AddUniqueIndex registers a unique secondary index on the Store's bucket, using the Store's value mapping. See the AddUniqueIndex function.
+*/
+func (s *Store[K, V]) AddUniqueIndex(name string, indexFunc func(value *V) (Key, bool)) error {
+	idx := newIndex(name, s.values.Unmarshal, indexFunc)
+	idx.unique = true
+	return s.bb.addIndex(idx)
+}
+
+/*
+AddIndex registers a secondary index on the Store's bucket, using the Store's value mapping. See the AddIndex function.
+*/
+func (s *Store[K, V]) AddIndex(name string, indexFunc func(value *V) (Key, bool)) error {
+	return s.bb.addIndex(newIndex(name, s.values.Unmarshal, indexFunc))
+}
+
+func newIndex[V any](name string, unmarshal func([]byte, *V) error, indexFunc func(value *V) (Key, bool)) *index {
+	indexKey := func(b []byte) (key []byte, ok bool, err error) {
+		var value V
+		if unmarshal(b, &value) != nil {
+			return
+		}
+		var k Key
+		if k, ok = indexFunc(&value); !ok {
+			return
+		}
+		key, err = EncodeKey(k)
+		return
+	}
+	return &index{name: []byte(name), indexKey: indexKey}
+}
+
+func (bb *Bucketeer) addIndex(idx *index) (err error) {
+	bf := func(b *bolt.Bucket) error {
+		return idx.rebuild(b, bb.path)
+	}
+	// if the bucket does not exist yet, the index is created with its first value
+	if err = bb.WithStrict(true).Update(bf); errors.Is(err, ErrBucketNotFound) {
+		err = nil
+	} else if err != nil {
+		return
+	}
+	modifyHooks(bb.db, bb.path, func(h *pathHooks) {
+		indexes := make([]*index, 0, len(h.indexes)+1)
+		for _, existing := range h.indexes {
+			if !bytes.Equal(existing.name, idx.name) {
+				indexes = append(indexes, existing)
+			}
+		}
+		h.indexes = append(indexes, idx)
+	})
+	return
+}
+
+/*
+RemoveIndex unregisters an index and deletes its entries.
+*/
+func (bb *Bucketeer) RemoveIndex(name string) error {
+	modifyHooks(bb.db, bb.path, func(h *pathHooks) {
+		indexes := make([]*index, 0, len(h.indexes))
+		for _, existing := range h.indexes {
+			if string(existing.name) != name {
+				indexes = append(indexes, existing)
+			}
+		}
+		h.indexes = indexes
+	})
+	bf := func(b *bolt.Bucket) (err error) {
+		var indexes *bolt.Bucket
+		if indexes = indexesBucket(b); indexes == nil || indexes.Bucket([]byte(name)) == nil {
+			return
+		}
+		err = indexes.DeleteBucket([]byte(name))
+		return
+	}
+	return bb.Update(bf)
+}
+
+/*
+RebuildIndex deletes a registered index's entries and rebuilds it from the bucket's values.
+*/
+func (bb *Bucketeer) RebuildIndex(name string) error {
+	var idx *index
+	if h := lookupHooks(bb.db, bb.path); h != nil {
+		for _, existing := range h.indexes {
+			if string(existing.name) == name {
+				idx = existing
+			}
+		}
+	}
+	if idx == nil {
+		return fmt.Errorf("Index %q is not registered for bucket %s", name, bb.path.String())
+	}
+	bf := func(b *bolt.Bucket) error {
+		return idx.rebuild(b, bb.path)
+	}
+	return bb.Update(bf)
+}
+
+func indexesBucket(b *bolt.Bucket) *bolt.Bucket {
+	if meta := b.Bucket(metaBucketName); meta != nil {
+		return meta.Bucket(indexBucketName)
+	}
+	return nil
+}
+
+func (idx *index) bucket(b *bolt.Bucket) *bolt.Bucket {
+	if indexes := indexesBucket(b); indexes != nil {
+		return indexes.Bucket(idx.name)
+	}
+	return nil
+}
+
+func (idx *index) ensureBucket(b *bolt.Bucket) (ib *bolt.Bucket, err error) {
+	if ib = idx.bucket(b); ib != nil {
+		return
+	}
+	var meta, indexes *bolt.Bucket
+	if meta, err = b.CreateBucketIfNotExists(metaBucketName); err != nil {
+		return
+	}
+	if indexes, err = meta.CreateBucketIfNotExists(indexBucketName); err != nil {
+		return
+	}
+	ib, err = indexes.CreateBucket(idx.name)
+	return
+}
+
+func (idx *index) rebuild(b *bolt.Bucket, path Path) (err error) {
+	if indexes := indexesBucket(b); indexes != nil && indexes.Bucket(idx.name) != nil {
+		if err = indexes.DeleteBucket(idx.name); err != nil {
+			return
+		}
+	}
+	var ib *bolt.Bucket
+	if ib, err = idx.ensureBucket(b); err != nil {
+		return
+	}
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			continue
+		}
+		var ik []byte
+		var ok bool
+		if ik, ok, err = idx.indexKey(v); err != nil {
+			return
+		}
+		if !ok {
+			continue
+		}
+		if err = idx.checkUnique(b, ib, path, ik, k); err != nil {
+			return
+		}
+		if err = ib.Put(indexEntryKey(ik, k), []byte{}); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/*
+update replaces the index entry for a key after its value has changed from the old value to the new value, either of which may be nil.
+*/
+func (idx *index) update(b *bolt.Bucket, key []byte, oldValue []byte, newValue []byte) (err error) {
+	var oldKey, newKey []byte
+	var oldOk, newOk bool
+	if oldValue != nil {
+		if oldKey, oldOk, err = idx.indexKey(oldValue); err != nil {
+			return
+		}
+	}
+	if newValue != nil {
+		if newKey, newOk, err = idx.indexKey(newValue); err != nil {
+			return
+		}
+	}
+	if oldOk == newOk && bytes.Equal(oldKey, newKey) {
+		return
+	}
+	var ib *bolt.Bucket
+	if ib, err = idx.ensureBucket(b); err != nil {
+		return
+	}
+	if oldOk {
+		if err = ib.Delete(indexEntryKey(oldKey, key)); err != nil {
+			return
+		}
+	}
+	if newOk {
+		err = ib.Put(indexEntryKey(newKey, key), []byte{})
+	}
+	return
+}
+
+/*
+check returns a UniqueViolationError if the index is unique and the key's new value has an index key which another key already has. It is called before the index entries are updated, so that a violating write changes nothing.
+*/
+func (idx *index) check(b *bolt.Bucket, path Path, key []byte, newValue []byte) (err error) {
+	if !idx.unique || newValue == nil {
+		return
+	}
+	var ib *bolt.Bucket
+	if ib = idx.bucket(b); ib == nil {
+		return
+	}
+	var newKey []byte
+	var ok bool
+	if newKey, ok, err = idx.indexKey(newValue); err != nil || !ok {
+		return
+	}
+	err = idx.checkUnique(b, ib, path, newKey, key)
+	return
+}
+
+/*
+checkUnique returns a UniqueViolationError if the index is unique and has an entry for the index key with a different key which has not expired.
+*/
+func (idx *index) checkUnique(b *bolt.Bucket, ib *bolt.Bucket, path Path, indexKey []byte, key []byte) (err error) {
+	if !idx.unique {
+		return
+	}
+	prefix := NewTupleKey(indexKey).KeyBytes()
+	c := NewTuplePrefixCursor(ib, prefix, ScanOptions{})
+	for c.Next() {
+		var conflict []byte
+		if _, conflict, err = decodeIndexEntryKey(c.Key()); err != nil {
+			return
+		}
+		if !bytes.Equal(conflict, key) && !isExpired(b, conflict) {
+			err = &UniqueViolationError{Path: path, Index: string(idx.name), Key: append([]byte{}, key...), Conflict: conflict}
+			return
+		}
+	}
+	return
+}
+
+func indexEntryKey(indexKey []byte, key []byte) []byte {
+	return NewTupleKey(indexKey, key).KeyBytes()
+}
+
+func decodeIndexEntryKey(b []byte) (indexKey []byte, key []byte, err error) {
+	var k TupleKey
+	if k, err = DecodeTupleKey(b); err != nil {
+		return
+	}
+	ok := len(k) == 2
+	if ok {
+		indexKey, ok = k[0].([]byte)
+	}
+	if ok {
+		key, ok = k[1].([]byte)
+	}
+	if !ok {
+		err = fmt.Errorf("Invalid index entry %q", b)
+	}
+	return
+}
+
+/*
+QueryIndex calls the provided function with the key and value of each value in the bucket with the provided index key, in key order. The byte slices are only valid within the scope of the function. Returning ErrStopIteration from the function ends the query without an error.
+*/
+func (bb *Bucketeer) QueryIndex(name string, indexKey Key, queryFunc func(key []byte, value []byte) error) (err error) {
+	var ik []byte
+	if ik, err = EncodeKey(indexKey); err != nil {
+		return
+	}
+	prefix := NewTupleKey(ik).KeyBytes()
+	scanFunc := func(_ []byte, key []byte, value []byte) error {
+		return queryFunc(key, value)
+	}
+	err = bb.scanIndex(name, prefix, tuplePrefixEnd(prefix), ScanOptions{ToExclusive: true}, scanFunc)
+	return
+}
+
+/*
+QueryIndexKeys returns the keys of the values in the bucket with the provided index key, in key order.
+*/
+func (bb *Bucketeer) QueryIndexKeys(name string, indexKey Key) (keys [][]byte, err error) {
+	queryFunc := func(key []byte, value []byte) error {
+		keys = append(keys, append([]byte{}, key...))
+		return nil
+	}
+	err = bb.QueryIndex(name, indexKey, queryFunc)
+	return
+}
+
+/*
+ScanIndex calls the provided function with the index key, key and value of each value in the bucket with an index key between the provided bounds, in index key order and then key order. A nil Key leaves that end of the range open. The byte slices are only valid within the scope of the function. Returning ErrStopIteration from the function ends the scan without an error.
+*/
+func (bb *Bucketeer) ScanIndex(name string, from, to Key, opts ScanOptions, scanFunc func(indexKey []byte, key []byte, value []byte) error) (err error) {
+	var fromBytes, toBytes []byte
+	if from != nil {
+		var ik []byte
+		if ik, err = EncodeKey(from); err != nil {
+			return
+		}
+		if fromBytes = NewTupleKey(ik).KeyBytes(); opts.FromExclusive {
+			fromBytes = tuplePrefixEnd(fromBytes)
+		}
+	}
+	if to != nil {
+		var ik []byte
+		if ik, err = EncodeKey(to); err != nil {
+			return
+		}
+		if toBytes = NewTupleKey(ik).KeyBytes(); !opts.ToExclusive {
+			toBytes = tuplePrefixEnd(toBytes)
+		}
+	}
+	// the bounds now select whole groups of entries
+	opts.FromExclusive, opts.ToExclusive = false, true
+	err = bb.scanIndex(name, fromBytes, toBytes, opts, scanFunc)
+	return
+}
+
+func (bb *Bucketeer) scanIndex(name string, from, to []byte, opts ScanOptions, scanFunc func(indexKey []byte, key []byte, value []byte) error) error {
+	idx := &index{name: []byte(name)}
+	bf := func(b *bolt.Bucket) (err error) {
+		var ib *bolt.Bucket
+		if ib = idx.bucket(b); ib == nil {
+			return fmt.Errorf("%w: %q in bucket %s", ErrIndexNotFound, name, bb.path.String())
+		}
+		cf := func(c *Cursor) (err error) {
+			var ik, key []byte
+			if ik, key, err = decodeIndexEntryKey(c.Key()); err != nil {
+				return
+			}
+			if value := b.Get(key); value != nil && !isExpired(b, key) {
+				err = scanFunc(ik, key, value)
+			}
+			return
+		}
+		err = iterate(NewRangeCursor(ib, from, to, opts), cf)
+		return
+	}
+	return bb.View(bf)
+}
+
+/*
+QueryIndex calls the provided function with each key and value in the Store with the provided index key. See Bucketeer.QueryIndex.
+*/
+func (s *Store[K, V]) QueryIndex(name string, indexKey Key, queryFunc func(key K, value V) error) error {
+	qf := func(k []byte, v []byte) (err error) {
+		var key K
+		if key, err = s.keys.Decode(k); err != nil {
+			return
+		}
+		var value V
+		if err = s.values.Unmarshal(v, &value); err != nil {
+			return
+		}
+		err = queryFunc(key, value)
+		return
+	}
+	return s.bb.QueryIndex(name, indexKey, qf)
+}
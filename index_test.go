@@ -0,0 +1,324 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type indexedUser struct {
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Age    int64  `json:"age"`
+}
+
+func TestIndex(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	users := NewStore(New(db, "users"), StringKeys, JsonValues[indexedUser]())
+	users.Bucketeer().EnsurePathBuckets()
+	users.Put("u1", indexedUser{Email: "a@example.com", Status: "active", Age: 30})
+
+	byStatus := func(u *indexedUser) (Key, bool) {
+		return NewStringKey(u.Status), u.Status != ""
+	}
+	if err = users.AddIndex("status", byStatus); err != nil {
+		t.Fatal(err.Error())
+	}
+	byAge := func(u *indexedUser) (Key, bool) {
+		return NewInt64Key(u.Age), true
+	}
+	if err = AddIndex(users.Bucketeer(), "age", nil, byAge); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	users.Put("u2", indexedUser{Email: "b@example.com", Status: "active", Age: 20})
+	users.Put("u3", indexedUser{Email: "c@example.com", Status: "disabled", Age: 40})
+	users.Put("u4", indexedUser{Email: "d@example.com", Age: 50})
+
+	// a Bucketeer created separately for the same path maintains the same indexes
+	other := New(db, "users")
+	txnFunc := func(t *BucketTx) error {
+		return t.ForStringKey("u1").PutJsonValue(indexedUser{Email: "a@example.com", Status: "disabled", Age: 30})
+	}
+	if err = other.Txn(txnFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	other.ForStringKey("u3").Delete()
+
+	queryKeys := func(name string, k Key) []string {
+		keys, err := other.QueryIndexKeys(name, k)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		var s []string
+		for _, key := range keys {
+			s = append(s, string(key))
+		}
+		return s
+	}
+	if keys := queryKeys("status", NewStringKey("active")); !reflect.DeepEqual(keys, []string{"u2"}) {
+		t.Fatalf("Expected [u2], got %v\n", keys)
+	}
+	if keys := queryKeys("status", NewStringKey("disabled")); !reflect.DeepEqual(keys, []string{"u1"}) {
+		t.Fatalf("Expected [u1], got %v\n", keys)
+	}
+
+	var emails []string
+	queryFunc := func(key string, u indexedUser) error {
+		emails = append(emails, u.Email)
+		return nil
+	}
+	if err = users.QueryIndex("status", NewStringKey("active"), queryFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(emails, []string{"b@example.com"}) {
+		t.Fatalf("Expected [b@example.com], got %v\n", emails)
+	}
+
+	var keys []string
+	scanFunc := func(indexKey []byte, key []byte, value []byte) error {
+		keys = append(keys, string(key))
+		return nil
+	}
+	if err = other.ScanIndex("age", NewInt64Key(20), NewInt64Key(50), ScanOptions{ToExclusive: true}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(keys, []string{"u2", "u1"}) {
+		t.Fatalf("Expected [u2 u1], got %v\n", keys)
+	}
+
+	// writes made directly to the bucket are picked up by a rebuild
+	other.Update(func(b *bolt.Bucket) error {
+		return b.Put([]byte("u5"), []byte(`{"status":"active"}`))
+	})
+	if err = other.RebuildIndex("status"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if keys := queryKeys("status", NewStringKey("active")); !reflect.DeepEqual(keys, []string{"u2", "u5"}) {
+		t.Fatalf("Expected [u2 u5], got %v\n", keys)
+	}
+
+	// registering an index again with a different extractor replaces its entries
+	byEmail := func(u *indexedUser) (Key, bool) {
+		return NewStringKey(u.Email), u.Email != ""
+	}
+	if err = users.AddIndex("status", byEmail); err != nil {
+		t.Fatal(err.Error())
+	}
+	if keys := queryKeys("status", NewStringKey("active")); len(keys) != 0 {
+		t.Fatalf("Expected no keys for the old index key, got %v\n", keys)
+	}
+	if keys := queryKeys("status", NewStringKey("b@example.com")); !reflect.DeepEqual(keys, []string{"u2"}) {
+		t.Fatalf("Expected [u2], got %v\n", keys)
+	}
+
+	if err = other.RemoveIndex("status"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err = other.QueryIndexKeys("status", NewStringKey("active")); !errors.Is(err, ErrIndexNotFound) {
+		t.Fatalf("Expected %v, got %v\n", ErrIndexNotFound, err)
+	}
+	other.RemoveIndex("age")
+	if h := lookupHooks(db, NewPath("users")); h != nil {
+		t.Fatalf("Expected no hooks after removing the indexes, got %v\n", h)
+	}
+}
+
+func TestUniqueIndex(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	users := New(db, "users")
+	users.EnsurePathBuckets()
+	users.ForStringKey("u1").PutJsonValue(indexedUser{Email: "a@example.com"})
+	users.ForStringKey("u2").PutJsonValue(indexedUser{Email: "a@example.com"})
+
+	byEmail := func(u *indexedUser) (Key, bool) {
+		return NewStringKey(u.Email), u.Email != ""
+	}
+	err = AddUniqueIndex(users, "email", JsonCodec, byEmail)
+	var violation *UniqueViolationError
+	if !errors.As(err, &violation) || string(violation.Key) != "u2" || string(violation.Conflict) != "u1" {
+		t.Fatalf("Expected a violation of u1 by u2, got %v\n", err)
+	}
+	if h := lookupHooks(db, NewPath("users")); h != nil {
+		t.Fatal("Expected the index not to be registered")
+	}
+
+	// the existing entries of an index built without the constraint are checked too
+	if err = AddIndex(users, "email", JsonCodec, byEmail); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = AddUniqueIndex(users, "email", JsonCodec, byEmail); !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Expected %v over an existing index, got %v\n", ErrUniqueViolation, err)
+	}
+	if h := lookupHooks(db, NewPath("users")); h == nil || len(h.indexes) != 1 || h.indexes[0].unique {
+		t.Fatal("Expected the earlier index to stay registered")
+	}
+
+	users.ForStringKey("u2").PutJsonValue(indexedUser{Email: "b@example.com"})
+	if err = AddUniqueIndex(users, "email", JsonCodec, byEmail); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = users.ForStringKey("u3").PutJsonValue(indexedUser{Email: "b@example.com"})
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("Expected %v, got %v\n", ErrUniqueViolation, err)
+	}
+	if exists, _ := users.ForStringKey("u3").Exists(); exists {
+		t.Fatal("Expected the violating write to be rolled back")
+	}
+
+	// rewriting a key with its own index key is not a violation
+	if err = users.ForStringKey("u2").PutJsonValue(indexedUser{Email: "b@example.com", Status: "active"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// an index key is released when its key moves to another index key
+	txnFunc := func(t *BucketTx) (err error) {
+		if err = t.ForStringKey("u1").PutJsonValue(indexedUser{Email: "c@example.com"}); err != nil {
+			return
+		}
+		return t.ForStringKey("u3").PutJsonValue(indexedUser{Email: "a@example.com"})
+	}
+	if err = users.Txn(txnFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// a violation handled within a transaction leaves the value and the index as they were
+	txnFunc = func(t *BucketTx) (err error) {
+		if err = t.ForStringKey("u2").PutJsonValue(indexedUser{Email: "a@example.com"}); errors.Is(err, ErrUniqueViolation) {
+			err = nil
+		}
+		return
+	}
+	if err = users.Txn(txnFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	var u2 indexedUser
+	if err = users.ForStringKey("u2").UnmarshalJsonValue(&u2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if u2.Email != "b@example.com" {
+		t.Fatalf("Expected u2 to keep b@example.com, got %s\n", u2.Email)
+	}
+	for email, expected := range map[string]string{"a@example.com": "u3", "b@example.com": "u2"} {
+		keys, err := users.QueryIndexKeys("email", NewStringKey(email))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(keys) != 1 || string(keys[0]) != expected {
+			t.Fatalf("Expected %s to index [%s], got %q\n", email, expected, keys)
+		}
+	}
+	users.RemoveIndex("email")
+}
+
+func TestIndexEmbeddedZero(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	users := New(db, "users")
+	users.EnsurePathBuckets()
+	users.ForStringKey("1").PutJsonValue(indexedUser{Email: "a"})
+	users.ForStringKey("2").PutJsonValue(indexedUser{Email: "a\x00b"})
+
+	byEmail := func(u *indexedUser) (Key, bool) {
+		return NewStringKey(u.Email), u.Email != ""
+	}
+	if err = AddUniqueIndex(users, "email", JsonCodec, byEmail); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer users.RemoveIndex("email")
+	if err = users.ForStringKey("1").PutJsonValue(indexedUser{Email: "a", Status: "active"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	keys, err := users.QueryIndexKeys("email", NewStringKey("a"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(keys) != 1 || string(keys[0]) != "1" {
+		t.Fatalf("Expected [1], got %q\n", keys)
+	}
+	var scanned []string
+	scanFunc := func(indexKey []byte, key []byte, value []byte) error {
+		scanned = append(scanned, string(key))
+		return nil
+	}
+	if err = users.ScanIndex("email", NewStringKey("a"), nil, ScanOptions{FromExclusive: true}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(scanned) != 1 || scanned[0] != "2" {
+		t.Fatalf("Expected [2], got %v\n", scanned)
+	}
+	scanned = nil
+	if err = users.ScanIndex("email", nil, NewStringKey("a"), ScanOptions{}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(scanned) != 1 || scanned[0] != "1" {
+		t.Fatalf("Expected [1], got %v\n", scanned)
+	}
+}
+
+func TestMetaBucketHidden(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	users := New(db, "users")
+	users.EnsurePathBuckets()
+	users.ForStringKey("u1").PutJsonValue(indexedUser{Email: "a@example.com"})
+	users.ForStringKey("u2").PutJsonValue(indexedUser{Email: "b@example.com"})
+	byEmail := func(u *indexedUser) (Key, bool) {
+		return NewStringKey(u.Email), true
+	}
+	if err = AddIndex(users, "email", JsonCodec, byEmail); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer users.RemoveIndex("email")
+
+	var paths []string
+	walkFunc := func(path Path, stats bolt.BucketStats, depth int) error {
+		paths = append(paths, path.String())
+		if stats.KeyN != 2 || stats.BucketN != 1 {
+			t.Fatalf("Expected 2 keys in 1 bucket, got %d keys in %d buckets\n", stats.KeyN, stats.BucketN)
+		}
+		return nil
+	}
+	if err = Walk(db, nil, walkFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(paths) != 1 || paths[0] != "[users]" {
+		t.Fatalf("Expected [[users]], got %v\n", paths)
+	}
+
+	var buf bytes.Buffer
+	if err = users.Export(&buf, ExportOptions{TextKeys: true}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Fatalf("Expected 3 records, got %d\n", lines)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/boltdb/bolt"
 )
@@ -12,6 +14,23 @@ type Key interface {
 	KeyBytes() []byte
 }
 
+/*
+KeyEncoder is implemented by keys whose byte form can fail to be produced. Key types which also implement KeyEncoder are encoded with EncodeKey instead of KeyBytes, so the error is returned rather than causing a panic.
+*/
+type KeyEncoder interface {
+	EncodeKey() ([]byte, error)
+}
+
+/*
+EncodeKey produces the byte form of the provided Key, using EncodeKey if the Key implements KeyEncoder.
+*/
+func EncodeKey(k Key) ([]byte, error) {
+	if ke, ok := k.(KeyEncoder); ok {
+		return ke.EncodeKey()
+	}
+	return k.KeyBytes(), nil
+}
+
 type ByteKey []byte
 
 func NewByteKey(key []byte) ByteKey {
@@ -57,6 +76,94 @@ func (k Int64Key) KeyBytes() (b []byte) {
 	return
 }
 
+/*
+KeyDecoder is implemented by pointers to key types which can be decoded from their byte form, so iteration and export tooling can present real key values.
+*/
+type KeyDecoder interface {
+	DecodeKey(b []byte) error
+}
+
+/*
+DecodeByteKey returns a copy of the provided bytes as a ByteKey.
+*/
+func DecodeByteKey(b []byte) (k ByteKey) {
+	k = make(ByteKey, len(b))
+	copy(k, b)
+	return
+}
+
+func (k *ByteKey) DecodeKey(b []byte) error {
+	*k = DecodeByteKey(b)
+	return nil
+}
+
+/*
+DecodeStringKey returns the provided bytes as a StringKey.
+*/
+func DecodeStringKey(b []byte) StringKey {
+	return StringKey(b)
+}
+
+func (k *StringKey) DecodeKey(b []byte) error {
+	*k = DecodeStringKey(b)
+	return nil
+}
+
+/*
+DecodeUint64Key reverses Uint64Key.KeyBytes. The key must be 8 bytes with the bits in big-endian ordering.
+*/
+func DecodeUint64Key(b []byte) (k Uint64Key, err error) {
+	if len(b) != 8 {
+		err = errors.New("Key is not 8 bytes")
+		return
+	}
+	k = Uint64Key(binary.BigEndian.Uint64(b))
+	return
+}
+
+func (k *Uint64Key) DecodeKey(b []byte) (err error) {
+	*k, err = DecodeUint64Key(b)
+	return
+}
+
+/*
+DecodeInt64Key reverses Int64Key.KeyBytes. The key must be 8 bytes in the shifted, big-endian form written by Int64Key.
+*/
+func DecodeInt64Key(b []byte) (k Int64Key, err error) {
+	if len(b) != 8 {
+		err = errors.New("Key is not 8 bytes")
+		return
+	}
+	k = Int64Key(uint64(1<<63) ^ binary.BigEndian.Uint64(b))
+	return
+}
+
+func (k *Int64Key) DecodeKey(b []byte) (err error) {
+	*k, err = DecodeInt64Key(b)
+	return
+}
+
+/*
+UnmarshalTextKey unmarshals the provided key bytes into the provided object.
+*/
+func UnmarshalTextKey(b []byte, keyObj encoding.TextUnmarshaler) error {
+	return keyObj.UnmarshalText(b)
+}
+
+/*
+UnmarshalBinaryKey unmarshals the provided key bytes into the provided object.
+*/
+func UnmarshalBinaryKey(b []byte, keyObj encoding.BinaryUnmarshaler) error {
+	return keyObj.UnmarshalBinary(b)
+}
+
+/*
+UnmarshalJsonKey unmarshals the provided key bytes into the provided object.
+*/
+func UnmarshalJsonKey(b []byte, keyObj interface{}) error {
+	return json.Unmarshal(b, keyObj)
+}
+
 type TextKey struct {
 	encoding.TextMarshaler
 }
@@ -67,12 +174,27 @@ func NewTextKey(keyObj encoding.TextMarshaler) *TextKey {
 
 func (k TextKey) KeyBytes() (b []byte) {
 	var err error
-	if b, err = k.MarshalText(); err != nil {
+	if b, err = k.EncodeKey(); err != nil {
 		panic(err.Error())
 	}
 	return
 }
 
+func (k TextKey) EncodeKey() ([]byte, error) {
+	return k.MarshalText()
+}
+
+/*
+DecodeKey unmarshals the key bytes into the wrapped object, which must also implement encoding.TextUnmarshaler.
+*/
+func (k *TextKey) DecodeKey(b []byte) error {
+	u, ok := k.TextMarshaler.(encoding.TextUnmarshaler)
+	if !ok {
+		return fmt.Errorf("Type %T does not implement encoding.TextUnmarshaler", k.TextMarshaler)
+	}
+	return UnmarshalTextKey(b, u)
+}
+
 type BinaryKey struct {
 	encoding.BinaryMarshaler
 }
@@ -83,12 +205,27 @@ func NewBinaryKey(keyObj encoding.BinaryMarshaler) *BinaryKey {
 
 func (k BinaryKey) KeyBytes() (b []byte) {
 	var err error
-	if b, err = k.MarshalBinary(); err != nil {
+	if b, err = k.EncodeKey(); err != nil {
 		panic(err.Error())
 	}
 	return
 }
 
+func (k BinaryKey) EncodeKey() ([]byte, error) {
+	return k.MarshalBinary()
+}
+
+/*
+DecodeKey unmarshals the key bytes into the wrapped object, which must also implement encoding.BinaryUnmarshaler.
+*/
+func (k *BinaryKey) DecodeKey(b []byte) error {
+	u, ok := k.BinaryMarshaler.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("Type %T does not implement encoding.BinaryUnmarshaler", k.BinaryMarshaler)
+	}
+	return UnmarshalBinaryKey(b, u)
+}
+
 type JsonKey struct {
 	keyObj interface{}
 }
@@ -99,18 +236,30 @@ func NewJsonKey(keyObj interface{}) *JsonKey {
 
 func (k JsonKey) KeyBytes() (b []byte) {
 	var err error
-	if b, err = json.Marshal(k.keyObj); err != nil {
+	if b, err = k.EncodeKey(); err != nil {
 		panic(err.Error())
 	}
 	return
 }
 
+func (k JsonKey) EncodeKey() ([]byte, error) {
+	return json.Marshal(k.keyObj)
+}
+
+/*
+DecodeKey unmarshals the key bytes into the wrapped object, which must be a pointer.
+*/
+func (k *JsonKey) DecodeKey(b []byte) error {
+	return UnmarshalJsonKey(b, k.keyObj)
+}
+
 /*
 Keyfarer encapsulates the components needed to resolve a key in BoltDB and provides convenience methods for setting and retrieving the value
 */
 type Keyfarer struct {
 	bb  *Bucketeer
 	key []byte
+	err error
 }
 
 func NewKeyfarer(bb *Bucketeer, key []byte) (kf *Keyfarer) {
@@ -121,6 +270,13 @@ func NewKeyfarer(bb *Bucketeer, key []byte) (kf *Keyfarer) {
 	return
 }
 
+/*
+Err returns the error, if any, which occurred while encoding the key. The same error is returned by every Keyfarer operation.
+*/
+func (kf *Keyfarer) Err() error {
+	return kf.err
+}
+
 /*
 PutByteValue sets the value for the key.
 */
@@ -128,7 +284,7 @@ func (kf *Keyfarer) PutByteValue(value []byte) error {
 	bf := func(b *bolt.Bucket) error {
 		return b.Put(kf.key, value)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 /*
@@ -138,7 +294,7 @@ func (kf *Keyfarer) PutStringValue(value string) (err error) {
 	bf := func(b *bolt.Bucket) error {
 		return b.Put(kf.key, []byte(value))
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 /*
@@ -148,7 +304,7 @@ func (kf *Keyfarer) PutTextValue(valueObj encoding.TextMarshaler) (err error) {
 	bf := func(b *bolt.Bucket) error {
 		return PutTextValue(b, kf.key, valueObj)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 /*
@@ -158,7 +314,7 @@ func (kf *Keyfarer) PutBinaryValue(valueObj encoding.BinaryMarshaler) error {
 	bf := func(b *bolt.Bucket) error {
 		return PutBinaryValue(b, kf.key, valueObj)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 /*
@@ -168,21 +324,32 @@ func (kf *Keyfarer) PutJsonValue(valueObj interface{}) error {
 	bf := func(b *bolt.Bucket) error {
 		return PutJsonValue(b, kf.key, valueObj)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
+}
+
+/*
+Put marshals the provided object with the provided Codec and sets it as the value for the key. If the Codec is nil, the Bucketeer's default Codec is used.
+*/
+func (kf *Keyfarer) Put(valueObj interface{}, codec Codec) error {
+	codec = kf.codecOrDefault(codec)
+	bf := func(b *bolt.Bucket) error {
+		return PutValue(b, kf.key, valueObj, codec)
+	}
+	return kf.update(bf)
 }
 
 func (kf *Keyfarer) PutVarintValue(value int64) error {
 	bf := func(b *bolt.Bucket) error {
 		return PutVarintValue(b, kf.key, value)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 func (kf *Keyfarer) PutUvarintValue(value uint64) error {
 	bf := func(b *bolt.Bucket) error {
 		return PutUvarintValue(b, kf.key, value)
 	}
-	return kf.bb.Update(bf)
+	return kf.update(bf)
 }
 
 /*
@@ -193,7 +360,7 @@ func (kf *Keyfarer) GetByteValue() (value []byte, err error) {
 		value = GetByteValue(b, kf.key)
 		return
 	}
-	err = kf.bb.View(bf)
+	err = kf.view(bf)
 	return
 }
 
@@ -207,7 +374,7 @@ func (kf *Keyfarer) GetStringValue() (value string, err error) {
 		}
 		return
 	}
-	err = kf.bb.View(bf)
+	err = kf.view(bf)
 	return
 }
 
@@ -218,7 +385,7 @@ func (kf *Keyfarer) UnmarshalTextValue(valueObj encoding.TextUnmarshaler) error
 	bf := func(b *bolt.Bucket) error {
 		return UnmarshalTextValue(b, kf.key, valueObj)
 	}
-	return kf.bb.View(bf)
+	return kf.view(bf)
 }
 
 /*
@@ -228,7 +395,7 @@ func (kf *Keyfarer) UnmarshalBinaryValue(valueObj encoding.BinaryUnmarshaler) er
 	bf := func(b *bolt.Bucket) error {
 		return UnmarshalBinaryValue(b, kf.key, valueObj)
 	}
-	return kf.bb.View(bf)
+	return kf.view(bf)
 }
 
 /*
@@ -238,7 +405,18 @@ func (kf *Keyfarer) UnmarshalJsonValue(valueObj interface{}) error {
 	bf := func(b *bolt.Bucket) error {
 		return UnmarshalJsonValue(b, kf.key, valueObj)
 	}
-	return kf.bb.View(bf)
+	return kf.view(bf)
+}
+
+/*
+Get gets the key's value and unmarshals it into the provided object with the provided Codec. If the Codec is nil, the Bucketeer's default Codec is used.
+*/
+func (kf *Keyfarer) Get(valueObj interface{}, codec Codec) error {
+	codec = kf.codecOrDefault(codec)
+	bf := func(b *bolt.Bucket) error {
+		return UnmarshalValue(b, kf.key, valueObj, codec)
+	}
+	return kf.view(bf)
 }
 
 func (kf *Keyfarer) GetVarintValue() (value int64, err error) {
@@ -246,7 +424,7 @@ func (kf *Keyfarer) GetVarintValue() (value int64, err error) {
 		value, err = GetVarintValue(b, kf.key)
 		return
 	}
-	err = kf.bb.View(bf)
+	err = kf.view(bf)
 	return
 }
 
@@ -255,33 +433,150 @@ func (kf *Keyfarer) GetUvarintValue() (value uint64, err error) {
 		value, err = GetUvarintValue(b, kf.key)
 		return
 	}
-	err = kf.bb.View(bf)
+	err = kf.view(bf)
+	return
+}
+
+/*
+PutInt64Value encodes the provided int64 into big-endian bytes and sets that as the value for the key.
+*/
+func (kf *Keyfarer) PutInt64Value(value int64) error {
+	bf := func(b *bolt.Bucket) error {
+		return PutInt64Value(b, kf.key, value)
+	}
+	return kf.update(bf)
+}
+
+/*
+GetInt64Value gets the key's value and converts its bytes into an int64 value. The value must be 8 bytes with the bits in big-endian ordering.
+*/
+func (kf *Keyfarer) GetInt64Value() (value int64, err error) {
+	bf := func(b *bolt.Bucket) (err error) {
+		value, err = GetInt64Value(b, kf.key)
+		return
+	}
+	err = kf.view(bf)
 	return
 }
 
+/*
+PutUint64Value encodes the provided uint64 into big-endian bytes and sets that as the value for the key.
+*/
+func (kf *Keyfarer) PutUint64Value(value uint64) error {
+	bf := func(b *bolt.Bucket) error {
+		return PutUint64Value(b, kf.key, value)
+	}
+	return kf.update(bf)
+}
+
+/*
+GetUint64Value gets the key's value and converts its bytes into a uint64 value. The value must be 8 bytes with the bits in big-endian ordering.
+*/
+func (kf *Keyfarer) GetUint64Value() (value uint64, err error) {
+	bf := func(b *bolt.Bucket) (err error) {
+		value, err = GetUint64Value(b, kf.key)
+		return
+	}
+	err = kf.view(bf)
+	return
+}
+
+/*
+IncrementInt64Value increments the key's value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero.
+*/
 func (kf *Keyfarer) IncrementInt64Value(value int64) (newValue int64, err error) {
 	bf := func(b *bolt.Bucket) (err error) {
 		newValue, err = IncrementInt64Value(b, kf.key, value)
 		return
 	}
-	err = kf.bb.Update(bf)
+	err = kf.update(bf)
 	return
 }
 
+/*
+IncrementUint64Value increments the key's value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero.
+*/
 func (kf *Keyfarer) IncrementUint64Value(value uint64) (newValue uint64, err error) {
 	bf := func(b *bolt.Bucket) (err error) {
 		newValue, err = IncrementUint64Value(b, kf.key, value)
 		return
 	}
-	err = kf.bb.Update(bf)
+	err = kf.update(bf)
+	return
+}
+
+/*
+IncrementVarintValue increments the key's variable-length encoded value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero.
+*/
+func (kf *Keyfarer) IncrementVarintValue(value int64) (newValue int64, err error) {
+	bf := func(b *bolt.Bucket) (err error) {
+		newValue, err = IncrementVarintValue(b, kf.key, value)
+		return
+	}
+	err = kf.update(bf)
 	return
 }
 
+/*
+IncrementUvarintValue increments the key's variable-length encoded value by the provided value, and returns the updated value. If the key does not exist, its value is treated as zero.
+*/
+func (kf *Keyfarer) IncrementUvarintValue(value uint64) (newValue uint64, err error) {
+	bf := func(b *bolt.Bucket) (err error) {
+		newValue, err = IncrementUvarintValue(b, kf.key, value)
+		return
+	}
+	err = kf.update(bf)
+	return
+}
+
+func (kf *Keyfarer) codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return kf.bb.Codec()
+	}
+	return codec
+}
+
 /*
 ViewValue gets the key's value and passes it to the provided function for arbitrary use. The byte slice is only valid within the scope of the function.
 */
 func (kf *Keyfarer) ViewValue(viewFunc func(value []byte) error) error {
-	return ViewValue(kf.bb.db, kf.bb.path, kf.key, viewFunc)
+	bf := func(b *bolt.Bucket) (err error) {
+		if value := b.Get(kf.key); value != nil {
+			err = viewFunc(value)
+		}
+		return
+	}
+	return kf.view(bf)
+}
+
+/*
+view executes the provided function in a View transaction. In strict mode, a KeyNotFoundError is returned if the key does not exist or has expired. If the key has expired, the function is not called.
+*/
+func (kf *Keyfarer) view(viewFunc func(b *bolt.Bucket) error) error {
+	if kf.err != nil {
+		return kf.err
+	}
+	bf := func(b *bolt.Bucket) error {
+		expired := isExpired(b, kf.key)
+		if kf.bb.strict && (expired || b.Get(kf.key) == nil) {
+			return &KeyNotFoundError{Path: kf.bb.path, Key: kf.key}
+		}
+		if expired {
+			return nil
+		}
+		return viewFunc(b)
+	}
+	return kf.bb.View(bf)
+}
+
+/*
+update executes the provided function in an Update transaction, and updates the bucket's indexes if the key's value changes.
+*/
+func (kf *Keyfarer) update(updateFunc func(b *bolt.Bucket) error) error {
+	if kf.err != nil {
+		return kf.err
+	}
+	return kf.bb.Update(kf.bb.writeFunc(kf.key, updateFunc))
 }
 
 /*
@@ -2,6 +2,7 @@ package bucketeer
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"math"
 	"os"
@@ -173,6 +174,55 @@ func TestInt64KeyOrder(t *testing.T) {
 	}
 }
 
+func TestDecodeKeys(t *testing.T) {
+
+	for _, expected := range []int64{math.MinInt64, -3, 0, 4295163905, math.MaxInt64} {
+		k, err := DecodeInt64Key(NewInt64Key(expected).KeyBytes())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if int64(k) != expected {
+			t.Fatalf("Expected %d, got %d\n", expected, k)
+		}
+	}
+
+	for _, expected := range []uint64{0, 4295163905, math.MaxUint64} {
+		k, err := DecodeUint64Key(NewUint64Key(expected).KeyBytes())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if uint64(k) != expected {
+			t.Fatalf("Expected %d, got %d\n", expected, k)
+		}
+	}
+
+	if _, err := DecodeUint64Key([]byte{1, 2, 3}); err == nil {
+		t.Fatal("Expected an error decoding a 3-byte key")
+	}
+
+	expected := time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var actual time.Time
+	decoders := []KeyDecoder{
+		NewTextKey(&actual),
+		NewBinaryKey(&actual),
+		NewJsonKey(&actual),
+	}
+	encoders := []Key{
+		NewTextKey(expected),
+		NewBinaryKey(expected),
+		NewJsonKey(expected),
+	}
+	for i, kd := range decoders {
+		actual = time.Time{}
+		if err := kd.DecodeKey(encoders[i].KeyBytes()); err != nil {
+			t.Fatal(err.Error())
+		}
+		if !expected.Equal(actual) {
+			t.Fatalf("Expected %v, got %v\n", expected, actual)
+		}
+	}
+}
+
 func TestTextKey(t *testing.T) {
 
 	k := NewTextKey(time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC))
@@ -208,6 +258,29 @@ func TestJsonKey(t *testing.T) {
 	}
 }
 
+func TestKeyEncoderError(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	kf := b.ForJsonKey(make(chan int))
+	if kf.Err() == nil {
+		t.Fatal("Expected an error encoding a channel as JSON")
+	}
+	if err = kf.PutStringValue("v1"); err != kf.Err() {
+		t.Fatalf("Expected %v, got %v\n", kf.Err(), err)
+	}
+	if _, err = kf.GetStringValue(); err != kf.Err() {
+		t.Fatalf("Expected %v, got %v\n", kf.Err(), err)
+	}
+}
+
 func TestByteValues(t *testing.T) {
 
 	db, err := bolt.Open(tempfile(), 0666, nil)
@@ -254,6 +327,94 @@ func TestStringValues(t *testing.T) {
 	}
 }
 
+func TestStrictMode(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	if err = b.ForStringKey("k1").PutStringValue("v1"); err != nil {
+		t.Fatalf("Expected no error in non-strict mode, got %s\n", err.Error())
+	}
+
+	b = b.WithStrict(true)
+	err = b.ForStringKey("k1").PutStringValue("v1")
+	var bucketErr *BucketNotFoundError
+	if !errors.Is(err, ErrBucketNotFound) || !errors.As(err, &bucketErr) {
+		t.Fatalf("Expected ErrBucketNotFound, got %v\n", err)
+	}
+	if actual := bucketErr.Path.String(); actual != "[test]" {
+		t.Fatalf("Expected path [test], got %s\n", actual)
+	}
+
+	b.EnsurePathBuckets()
+	if err = b.ForStringKey("k1").PutStringValue("v1"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err = b.ForStringKey("k1").GetStringValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = b.ForStringKey("k2").GetStringValue()
+	var keyErr *KeyNotFoundError
+	if !errors.Is(err, ErrKeyNotFound) || !errors.As(err, &keyErr) {
+		t.Fatalf("Expected ErrKeyNotFound, got %v\n", err)
+	}
+	if actual := string(keyErr.Key); actual != "k2" {
+		t.Fatalf("Expected key k2, got %s\n", actual)
+	}
+}
+
+func TestIncrementValues(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	var u uint64
+	if u, err = b.ForStringKey("u64").IncrementUint64Value(3); err != nil {
+		t.Fatal(err.Error())
+	}
+	if u != 3 {
+		t.Fatalf("Expected 3, got %d\n", u)
+	}
+	if _, err = b.ForStringKey("u64").IncrementUint64Value(math.MaxUint64); err != ErrOverflow {
+		t.Fatalf("Expected ErrOverflow, got %v\n", err)
+	}
+
+	var v int64
+	if v, err = b.ForStringKey("varint").IncrementVarintValue(-5); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, err = b.ForStringKey("varint").IncrementVarintValue(2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != -3 {
+		t.Fatalf("Expected -3, got %d\n", v)
+	}
+	if v, err = b.ForStringKey("varint").GetVarintValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != -3 {
+		t.Fatalf("Expected -3, got %d\n", v)
+	}
+
+	if u, err = b.ForStringKey("uvarint").IncrementUvarintValue(300); err != nil {
+		t.Fatal(err.Error())
+	}
+	if u != 300 {
+		t.Fatalf("Expected 300, got %d\n", u)
+	}
+}
+
 // tempfile returns a temporary file path.
 func tempfile() string {
 	f, err := ioutil.TempFile("", "bolt-")
@@ -0,0 +1,83 @@
+package bucketeer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ErrDeleteKey can be returned from a modify function to delete the key instead of writing the modified value.
+*/
+var ErrDeleteKey = errors.New("Delete key")
+
+/*
+ModifyValue loads the key's value into the provided object with the provided Codec, calls the modify function, and writes the object back, all in the same Update transaction. The object must be a non-nil pointer, or an error is returned; it is reset to its zero value before loading, so it remains zero if the key does not exist. If the modify function returns ErrDeleteKey, the key is deleted; any other error aborts the transaction. If the Codec is nil, the Bucketeer's default Codec is used.
+*/
+func (kf *Keyfarer) ModifyValue(valueObj interface{}, codec Codec, modifyFunc func() error) error {
+	ptr := reflect.ValueOf(valueObj)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("Type %T is not a non-nil pointer", valueObj)
+	}
+	codec = kf.codecOrDefault(codec)
+	bf := func(b *bolt.Bucket) (err error) {
+		v := ptr.Elem()
+		v.Set(reflect.Zero(v.Type()))
+		if err = UnmarshalValue(b, kf.key, valueObj, codec); err != nil {
+			return
+		}
+		if err = modifyFunc(); err == ErrDeleteKey {
+			return b.Delete(kf.key)
+		} else if err != nil {
+			return
+		}
+		return PutValue(b, kf.key, valueObj, codec)
+	}
+	return kf.update(bf)
+}
+
+/*
+ModifyJsonValue loads the key's JSON value into the provided object, calls the modify function, and writes the object back in its JSON form, all in the same Update transaction. See ModifyValue.
+*/
+func (kf *Keyfarer) ModifyJsonValue(valueObj interface{}, modifyFunc func() error) error {
+	return kf.ModifyValue(valueObj, JsonCodec, modifyFunc)
+}
+
+/*
+Modify loads the key's value with the provided Codec, passes it to the modify function, and writes the result back, all in the same Update transaction. The modify function receives the zero value if the key does not exist. If the modify function returns ErrDeleteKey, the key is deleted. If the Codec is nil, the Bucketeer's default Codec is used.
+*/
+func Modify[V any](kf *Keyfarer, codec Codec, modifyFunc func(value *V) error) error {
+	var value V
+	mf := func() error {
+		return modifyFunc(&value)
+	}
+	return kf.ModifyValue(&value, codec, mf)
+}
+
+/*
+Modify loads the key's value, passes it to the modify function, and writes the result back, all in the same Update transaction. The modify function receives the zero value if the key does not exist. If the modify function returns ErrDeleteKey, the key is deleted.
+*/
+func (s *Store[K, V]) Modify(key K, modifyFunc func(value *V) error) error {
+	kf := s.ForKey(key)
+	bf := func(b *bolt.Bucket) (err error) {
+		var value V
+		if v := b.Get(kf.key); v != nil {
+			if err = s.values.Unmarshal(v, &value); err != nil {
+				return
+			}
+		}
+		if err = modifyFunc(&value); err == ErrDeleteKey {
+			return b.Delete(kf.key)
+		} else if err != nil {
+			return
+		}
+		var v []byte
+		if v, err = s.values.Marshal(value); err != nil {
+			return
+		}
+		return b.Put(kf.key, v)
+	}
+	return kf.update(bf)
+}
@@ -0,0 +1,62 @@
+package bucketeer
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type modifyCart struct {
+	Items []string
+}
+
+func TestModify(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	kf := b.ForStringKey("cart")
+
+	addItem := func(item string) func(cart *modifyCart) error {
+		return func(cart *modifyCart) error {
+			cart.Items = append(cart.Items, item)
+			return nil
+		}
+	}
+	if err = Modify(kf, JsonCodec, addItem("apple")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = Modify(kf, JsonCodec, addItem("pear")); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var cart modifyCart
+	if err = kf.UnmarshalJsonValue(&cart); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(cart.Items) != 2 || cart.Items[1] != "pear" {
+		t.Fatalf("Expected [apple pear], got %v\n", cart.Items)
+	}
+
+	deleteIfFull := func() error {
+		if len(cart.Items) >= 2 {
+			return ErrDeleteKey
+		}
+		return nil
+	}
+	if err = kf.ModifyJsonValue(&cart, deleteIfFull); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ := kf.GetByteValue(); v != nil {
+		t.Fatalf("Expected key to be deleted, got %s\n", v)
+	}
+
+	if err = kf.ModifyJsonValue(cart, deleteIfFull); err == nil {
+		t.Fatal("Expected an error modifying a non-pointer")
+	}
+}
@@ -2,6 +2,8 @@ package bucketeer
 
 import (
 	"bytes"
+	"errors"
+	"strings"
 )
 
 /*
@@ -20,6 +22,24 @@ func NewPath(buckets ...string) (p Path) {
 	return
 }
 
+/*
+ParsePath parses a Path from the form produced by Path.String, e.g. "[root, branch, leaf]". The brackets are optional. Bucket names are separated by ", ", so names containing that sequence cannot be parsed.
+*/
+func ParsePath(s string) (p Path, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") != strings.HasSuffix(s, "]") {
+		err = errors.New("Path has unbalanced brackets")
+		return
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		p = Path{}
+		return
+	}
+	p = NewPath(strings.Split(s, ", ")...)
+	return
+}
+
 /*
 Nest allocates a new Path with the provided bucket name appended to the current path.
 */
@@ -24,3 +24,34 @@ func TestPath(t *testing.T) {
 		t.Fatalf("Expected length 3, got '%d'", actual)
 	}
 }
+
+func TestParsePath(t *testing.T) {
+
+	p := NewPath("root", "branch", "leaf")
+
+	actual, err := ParsePath(p.String())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if actual.String() != p.String() {
+		t.Fatalf("Expected %s, got %s", p.String(), actual.String())
+	}
+
+	if actual, err = ParsePath("root"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(actual) != 1 || string(actual[0]) != "root" {
+		t.Fatalf("Expected [root], got %s", actual.String())
+	}
+
+	if actual, err = ParsePath("[]"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(actual) != 0 {
+		t.Fatalf("Expected empty path, got %s", actual.String())
+	}
+
+	if _, err = ParsePath("[root"); err == nil {
+		t.Fatal("Expected an error for unbalanced brackets")
+	}
+}
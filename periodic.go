@@ -0,0 +1,65 @@
+package bucketeer
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+periodic runs a function every interval in a background goroutine, between calls to start and stop. It holds the goroutine state shared by Sweeper and Snapshotter.
+*/
+type periodic struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newPeriodic(interval time.Duration) periodic {
+	if interval <= 0 {
+		panic("Interval must be positive")
+	}
+	return periodic{interval: interval}
+}
+
+/*
+start starts a goroutine which calls the provided function every interval until stopped. The function receives a channel which is closed when stop is called, so that long-running work can end early. Calling start while the goroutine is running has no effect.
+*/
+func (p *periodic) start(tickFunc func(stop <-chan struct{})) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		return
+	}
+	p.stop, p.done = make(chan struct{}), make(chan struct{})
+	go p.run(p.stop, p.done, tickFunc)
+}
+
+/*
+halt stops the goroutine started by start and waits for any call in progress to finish.
+*/
+func (p *periodic) halt() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop, p.done = nil, nil
+}
+
+func (p *periodic) run(stop <-chan struct{}, done chan<- struct{}, tickFunc func(stop <-chan struct{})) {
+	defer close(done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			tickFunc(stop)
+		}
+	}
+}
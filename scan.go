@@ -0,0 +1,139 @@
+package bucketeer
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+/*
+ScanOptions controls the bounds, direction and size of a range or prefix scan. The zero value scans in key order with inclusive bounds and no limit.
+*/
+type ScanOptions struct {
+	// FromExclusive excludes a key equal to the lower bound from the scan.
+	FromExclusive bool
+	// ToExclusive excludes a key equal to the upper bound from the scan.
+	ToExclusive bool
+	// Reverse scans from the upper bound down to the lower bound.
+	Reverse bool
+	// Limit is the maximum number of key-value pairs to visit; zero means no limit.
+	Limit int
+}
+
+/*
+NewRangeCursor creates a Cursor for the provided bucket which only visits keys between the lower and upper bounds. A nil bound leaves that end of the range open.
+*/
+func NewRangeCursor(b *bolt.Bucket, from, to []byte, opts ScanOptions) (c *Cursor) {
+	c = NewCursor(b, opts.Reverse)
+	c.from = from
+	c.to = to
+	c.opts = opts
+	return
+}
+
+/*
+NewPrefixCursor creates a Cursor for the provided bucket which only visits keys starting with the provided prefix. The bound exclusivity options are ignored.
+*/
+func NewPrefixCursor(b *bolt.Bucket, prefix []byte, opts ScanOptions) *Cursor {
+	return newPrefixCursor(b, prefix, prefixEnd(prefix), opts)
+}
+
+/*
+NewTuplePrefixCursor creates a Cursor for the provided bucket which only visits TupleKeys starting with the components encoded in the provided prefix. Unlike NewPrefixCursor, it does not visit keys whose component only starts with the prefix's last component followed by a zero byte. The bound exclusivity options are ignored.
+*/
+func NewTuplePrefixCursor(b *bolt.Bucket, prefix []byte, opts ScanOptions) *Cursor {
+	return newPrefixCursor(b, prefix, tuplePrefixEnd(prefix), opts)
+}
+
+func newPrefixCursor(b *bolt.Bucket, prefix, end []byte, opts ScanOptions) *Cursor {
+	opts.FromExclusive = false
+	opts.ToExclusive = true
+	return NewRangeCursor(b, prefix, end, opts)
+}
+
+/*
+ScanRange calls the provided function for each key-value pair in the current bucket with a key between the provided bounds. A nil Key leaves that end of the range open. The bounds should use the same Key type as the stored keys, e.g. Int64Key for time-ordered records.
+*/
+func (bb *Bucketeer) ScanRange(from, to Key, opts ScanOptions, scanFunc func(c *Cursor) error) (err error) {
+	var fromBytes, toBytes []byte
+	if fromBytes, err = encodeBound(from); err != nil {
+		return
+	}
+	if toBytes, err = encodeBound(to); err != nil {
+		return
+	}
+	bf := func(b *bolt.Bucket) error {
+		return iterate(NewRangeCursor(b, fromBytes, toBytes, opts), scanFunc)
+	}
+	err = bb.View(bf)
+	return
+}
+
+/*
+ScanPrefix calls the provided function for each key-value pair in the current bucket with a key starting with the bytes of the provided Key. If the Key is a TupleKey, only keys starting with the same whole components are visited.
+*/
+func (bb *Bucketeer) ScanPrefix(prefix Key, opts ScanOptions, scanFunc func(c *Cursor) error) (err error) {
+	var prefixBytes []byte
+	if prefixBytes, err = encodeBound(prefix); err != nil {
+		return
+	}
+	newCursor := prefixCursorFunc(prefix)
+	bf := func(b *bolt.Bucket) error {
+		return iterate(newCursor(b, prefixBytes, opts), scanFunc)
+	}
+	err = bb.View(bf)
+	return
+}
+
+/*
+ScanRangeInBucket calls the provided function in a View transaction for each key-value pair in the bucket with a key between the provided bounds. Returning ErrStopIteration from the function ends the scan without an error.
+*/
+func ScanRangeInBucket(db *bolt.DB, path Path, from, to []byte, opts ScanOptions, scanFunc func(c *Cursor) error) error {
+	bf := func(b *bolt.Bucket) error {
+		return iterate(NewRangeCursor(b, from, to, opts), scanFunc)
+	}
+	return ViewInBucket(db, path, bf)
+}
+
+/*
+ScanPrefixInBucket calls the provided function in a View transaction for each key-value pair in the bucket with a key starting with the provided prefix. Returning ErrStopIteration from the function ends the scan without an error.
+*/
+func ScanPrefixInBucket(db *bolt.DB, path Path, prefix []byte, opts ScanOptions, scanFunc func(c *Cursor) error) error {
+	bf := func(b *bolt.Bucket) error {
+		return iterate(NewPrefixCursor(b, prefix, opts), scanFunc)
+	}
+	return ViewInBucket(db, path, bf)
+}
+
+/*
+prefixCursorFunc returns the function which creates a prefix Cursor for the provided Key: NewTuplePrefixCursor for a TupleKey, and NewPrefixCursor otherwise.
+*/
+func prefixCursorFunc(prefix Key) func(b *bolt.Bucket, prefix []byte, opts ScanOptions) *Cursor {
+	if _, ok := prefix.(TupleKey); ok {
+		return NewTuplePrefixCursor
+	}
+	return NewPrefixCursor
+}
+
+/*
+encodeBound returns the bytes of the provided Key, or nil if the Key is nil.
+*/
+func encodeBound(k Key) ([]byte, error) {
+	if k == nil {
+		return nil, nil
+	}
+	return EncodeKey(k)
+}
+
+/*
+prefixEnd returns the smallest key which is greater than every key starting with the provided prefix, or nil if there is no such key.
+*/
+func prefixEnd(prefix []byte) (end []byte) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			end = make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,193 @@
+package bucketeer
+
+import (
+	"errors"
+)
+
+/*
+KeyMapper converts between the typed keys of a Store and their byte form in BoltDB.
+*/
+type KeyMapper[K any] struct {
+	Encode func(key K) Key
+	Decode func(b []byte) (K, error)
+}
+
+/*
+ByteKeys maps []byte keys as-is. Decoded keys are copies which remain valid outside of the transaction.
+*/
+var ByteKeys = KeyMapper[[]byte]{
+	Encode: func(key []byte) Key {
+		return NewByteKey(key)
+	},
+	Decode: func(b []byte) ([]byte, error) {
+		return DecodeByteKey(b), nil
+	},
+}
+
+/*
+StringKeys maps string keys as their raw bytes.
+*/
+var StringKeys = KeyMapper[string]{
+	Encode: func(key string) Key {
+		return NewStringKey(key)
+	},
+	Decode: func(b []byte) (string, error) {
+		return string(b), nil
+	},
+}
+
+/*
+Uint64Keys maps uint64 keys in the byte-sortable form used by Uint64Key.
+*/
+var Uint64Keys = KeyMapper[uint64]{
+	Encode: func(key uint64) Key {
+		return NewUint64Key(key)
+	},
+	Decode: func(b []byte) (key uint64, err error) {
+		var k Uint64Key
+		k, err = DecodeUint64Key(b)
+		key = uint64(k)
+		return
+	},
+}
+
+/*
+Int64Keys maps int64 keys in the byte-sortable form used by Int64Key.
+*/
+var Int64Keys = KeyMapper[int64]{
+	Encode: func(key int64) Key {
+		return NewInt64Key(key)
+	},
+	Decode: func(b []byte) (key int64, err error) {
+		var k Int64Key
+		k, err = DecodeInt64Key(b)
+		key = int64(k)
+		return
+	},
+}
+
+/*
+ValueMapper converts between the typed values of a Store and their byte form in BoltDB.
+*/
+type ValueMapper[V any] struct {
+	Marshal   func(value V) ([]byte, error)
+	Unmarshal func(b []byte, value *V) error
+}
+
+/*
+CodecValues creates a ValueMapper which stores values with the provided Codec. The Codec receives values as V and pointers as *V.
+*/
+func CodecValues[V any](codec Codec) ValueMapper[V] {
+	return ValueMapper[V]{
+		Marshal: func(value V) ([]byte, error) {
+			return codec.Marshal(value)
+		},
+		Unmarshal: func(b []byte, value *V) error {
+			return codec.Unmarshal(b, value)
+		},
+	}
+}
+
+/*
+JsonValues creates a ValueMapper which stores values in their JSON form.
+*/
+func JsonValues[V any]() ValueMapper[V] {
+	return CodecValues[V](JsonCodec)
+}
+
+/*
+Store binds a Bucketeer to a key mapping and a value mapping, providing typed access to the key-value pairs of its bucket.
+*/
+type Store[K any, V any] struct {
+	bb     *Bucketeer
+	keys   KeyMapper[K]
+	values ValueMapper[V]
+}
+
+/*
+NewStore creates a Store for the provided Bucketeer, key mapping and value mapping.
+*/
+func NewStore[K any, V any](bb *Bucketeer, keys KeyMapper[K], values ValueMapper[V]) (s *Store[K, V]) {
+	s = &Store[K, V]{
+		bb:     bb,
+		keys:   keys,
+		values: values,
+	}
+	return
+}
+
+/*
+Bucketeer returns the Bucketeer for the Store's bucket.
+*/
+func (s *Store[K, V]) Bucketeer() *Bucketeer {
+	return s.bb
+}
+
+/*
+ForKey creates a new Keyfarer for the provided key.
+*/
+func (s *Store[K, V]) ForKey(key K) *Keyfarer {
+	return s.bb.ForKey(s.keys.Encode(key))
+}
+
+/*
+Put marshals the provided value and sets it as the value for the key.
+*/
+func (s *Store[K, V]) Put(key K, value V) (err error) {
+	var v []byte
+	if v, err = s.values.Marshal(value); err != nil {
+		return
+	}
+	err = s.ForKey(key).PutByteValue(v)
+	return
+}
+
+/*
+Get gets the key's value and unmarshals it. If the key does not exist, the zero value is returned and found is false, even when the Bucketeer is in strict mode.
+*/
+func (s *Store[K, V]) Get(key K) (value V, found bool, err error) {
+	vf := func(v []byte) error {
+		found = true
+		return s.values.Unmarshal(v, &value)
+	}
+	if err = s.ForKey(key).ViewValue(vf); errors.Is(err, ErrKeyNotFound) {
+		err = nil
+	}
+	return
+}
+
+/*
+Iterate calls the provided function with each key and value in the Store, in key order. Returning ErrStopIteration from the function ends the iteration without an error.
+*/
+func (s *Store[K, V]) Iterate(iterFunc func(key K, value V) error) error {
+	return s.bb.Iterate(s.cursorFunc(iterFunc))
+}
+
+/*
+IterateReverse calls the provided function with each key and value in the Store, in reverse key order. Returning ErrStopIteration from the function ends the iteration without an error.
+*/
+func (s *Store[K, V]) IterateReverse(iterFunc func(key K, value V) error) error {
+	return s.bb.IterateReverse(s.cursorFunc(iterFunc))
+}
+
+/*
+ScanRange calls the provided function with each key and value in the Store which has a key between the provided bounds.
+*/
+func (s *Store[K, V]) ScanRange(from, to K, opts ScanOptions, scanFunc func(key K, value V) error) error {
+	return s.bb.ScanRange(s.keys.Encode(from), s.keys.Encode(to), opts, s.cursorFunc(scanFunc))
+}
+
+func (s *Store[K, V]) cursorFunc(f func(key K, value V) error) func(c *Cursor) error {
+	return func(c *Cursor) (err error) {
+		var key K
+		if key, err = s.keys.Decode(c.Key()); err != nil {
+			return
+		}
+		var value V
+		if err = s.values.Unmarshal(c.Value(), &value); err != nil {
+			return
+		}
+		err = f(key, value)
+		return
+	}
+}
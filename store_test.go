@@ -0,0 +1,58 @@
+package bucketeer
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type storeUser struct {
+	Name  string
+	Email string
+}
+
+func TestStore(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "users")
+	b.EnsurePathBuckets()
+	users := NewStore(b, Uint64Keys, JsonValues[storeUser]())
+
+	if err = users.Put(42, storeUser{"Alice", "alice@example.com"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err = users.Put(7, storeUser{"Bob", "bob@example.com"}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	u, found, err := users.Get(42)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !found || u.Name != "Alice" {
+		t.Fatalf("Expected Alice, got %v (found: %t)\n", u, found)
+	}
+
+	if _, found, err = users.Get(1); err != nil {
+		t.Fatal(err.Error())
+	} else if found {
+		t.Fatal("Expected key 1 to not be found")
+	}
+
+	var ids []uint64
+	iterFunc := func(id uint64, u storeUser) error {
+		ids = append(ids, id)
+		return nil
+	}
+	if err = users.Iterate(iterFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ids) != 2 || ids[0] != 7 || ids[1] != 42 {
+		t.Fatalf("Expected [7 42], got %v\n", ids)
+	}
+}
@@ -9,21 +9,13 @@ import (
 /*
 PutTextValue marshals the provided object into its textual form and sets it as the value for the key.
 */
-func PutTextValue(b *bolt.Bucket, key []byte, valueObj encoding.TextMarshaler) (err error) {
-	var value []byte
-	if value, err = valueObj.MarshalText(); err != nil {
-		return
-	}
-	err = b.Put(key, value)
-	return
+func PutTextValue(b *bolt.Bucket, key []byte, valueObj encoding.TextMarshaler) error {
+	return PutValue(b, key, valueObj, TextCodec)
 }
 
 /*
 UnmarshalTextValue gets the key's value and unmarshals it into the provided object.
 */
-func UnmarshalTextValue(b *bolt.Bucket, key []byte, valueObj encoding.TextUnmarshaler) (err error) {
-	if value := b.Get(key); value != nil {
-		err = valueObj.UnmarshalText(value)
-	}
-	return
+func UnmarshalTextValue(b *bolt.Bucket, key []byte, valueObj encoding.TextUnmarshaler) error {
+	return UnmarshalValue(b, key, valueObj, TextCodec)
 }
@@ -0,0 +1,291 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// ttlBucketName maps each expiring key to its expiry time
+	ttlBucketName = []byte("ttl")
+	// ttlQueueBucketName holds an entry for each expiring key, ordered by expiry time
+	ttlQueueBucketName = []byte("ttl_queue")
+)
+
+/*
+timeNow returns the current time; tests replace it to control expiry.
+*/
+var timeNow = time.Now
+
+/*
+PutWithTTL sets the value for the key, which expires after the provided duration. An expired value is treated as absent by reads through a Keyfarer, Cursor or Store, and is deleted by the next write to the key or by a Sweeper. Any other write which changes or deletes the value removes its expiry. The expiry times are kept in the bucket's nested bucket named MetaBucketName.
+*/
+func (kf *Keyfarer) PutWithTTL(value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("TTL must be positive")
+	}
+	bf := func(b *bolt.Bucket) (err error) {
+		if err = b.Put(kf.key, value); err != nil {
+			return
+		}
+		err = setExpiry(b, kf.key, timeNow().Add(ttl).UnixNano())
+		return
+	}
+	return kf.update(bf)
+}
+
+/*
+TTL returns the time remaining before the key's value expires. If the key does not exist, has expired, or does not expire, ok is false.
+*/
+func (kf *Keyfarer) TTL() (ttl time.Duration, ok bool, err error) {
+	if kf.err != nil {
+		err = kf.err
+		return
+	}
+	bf := func(b *bolt.Bucket) (err error) {
+		var expiry int64
+		if expiry, ok = expiryOf(b, kf.key); ok {
+			if ttl = time.Duration(expiry - timeNow().UnixNano()); ttl <= 0 {
+				ttl, ok = 0, false
+			}
+		}
+		return
+	}
+	err = kf.bb.View(bf)
+	return
+}
+
+/*
+PutWithTTL marshals the provided value and sets it as the value for the key, which expires after the provided duration. See Keyfarer.PutWithTTL.
+*/
+func (s *Store[K, V]) PutWithTTL(key K, value V, ttl time.Duration) (err error) {
+	var v []byte
+	if v, err = s.values.Marshal(value); err != nil {
+		return
+	}
+	err = s.ForKey(key).PutWithTTL(v, ttl)
+	return
+}
+
+func ttlBucket(b *bolt.Bucket) *bolt.Bucket {
+	if meta := b.Bucket(metaBucketName); meta != nil {
+		return meta.Bucket(ttlBucketName)
+	}
+	return nil
+}
+
+func ttlQueueBucket(b *bolt.Bucket) *bolt.Bucket {
+	if meta := b.Bucket(metaBucketName); meta != nil {
+		return meta.Bucket(ttlQueueBucketName)
+	}
+	return nil
+}
+
+/*
+expiryOf returns the expiry time of the key in Unix nanoseconds, if it has one.
+*/
+func expiryOf(b *bolt.Bucket, key []byte) (expiry int64, ok bool) {
+	if ttl := ttlBucket(b); ttl != nil {
+		if v := ttl.Get(key); v != nil {
+			var k Int64Key
+			if k.DecodeKey(v) == nil {
+				expiry, ok = int64(k), true
+			}
+		}
+	}
+	return
+}
+
+/*
+isExpired reports whether the key has an expiry time which has passed.
+*/
+func isExpired(b *bolt.Bucket, key []byte) bool {
+	expiry, ok := expiryOf(b, key)
+	return ok && expiry <= timeNow().UnixNano()
+}
+
+func setExpiry(b *bolt.Bucket, key []byte, expiry int64) (err error) {
+	if err = clearExpiry(b, key); err != nil {
+		return
+	}
+	var meta, ttl, queue *bolt.Bucket
+	if meta, err = b.CreateBucketIfNotExists(metaBucketName); err != nil {
+		return
+	}
+	if ttl, err = meta.CreateBucketIfNotExists(ttlBucketName); err != nil {
+		return
+	}
+	if queue, err = meta.CreateBucketIfNotExists(ttlQueueBucketName); err != nil {
+		return
+	}
+	expiryBytes := NewInt64Key(expiry).KeyBytes()
+	if err = ttl.Put(key, expiryBytes); err != nil {
+		return
+	}
+	err = queue.Put(append(expiryBytes, key...), []byte{})
+	return
+}
+
+func clearExpiry(b *bolt.Bucket, key []byte) (err error) {
+	ttl := ttlBucket(b)
+	if ttl == nil {
+		return
+	}
+	expiryBytes := ttl.Get(key)
+	if expiryBytes == nil {
+		return
+	}
+	queueKey := append(append([]byte{}, expiryBytes...), key...)
+	if err = ttl.Delete(key); err != nil {
+		return
+	}
+	err = ttlQueueBucket(b).Delete(queueKey)
+	return
+}
+
+/*
+expireKey deletes the key and its expiry if it has expired, before a write sees it.
+*/
+func expireKey(b *bolt.Bucket, key []byte) (err error) {
+	if !isExpired(b, key) {
+		return
+	}
+	if err = clearExpiry(b, key); err != nil {
+		return
+	}
+	err = b.Delete(key)
+	return
+}
+
+/*
+Sweeper deletes expired keys from a bucket in the background. Keys are deleted through Keyfarers, so indexes are updated.
+*/
+type Sweeper struct {
+	bb        *Bucketeer
+	batchSize int
+	loop      periodic
+
+	// OnSweep, if set, is called after each sweep made by the background goroutine started with Start. It must be set before Start is called.
+	OnSweep func(count int, err error)
+}
+
+/*
+NewSweeper creates a Sweeper which sweeps the Bucketeer's bucket every interval, deleting at most batchSize keys in each transaction so that writers are not blocked for long. If the interval or batch size is not positive, this function will panic.
+*/
+func NewSweeper(bb *Bucketeer, interval time.Duration, batchSize int) (s *Sweeper) {
+	if batchSize <= 0 {
+		panic("Batch size must be positive")
+	}
+	s = &Sweeper{
+		bb:        bb,
+		batchSize: batchSize,
+		loop:      newPeriodic(interval),
+	}
+	return
+}
+
+/*
+Sweep deletes every key which has expired, in transactions of at most the batch size, and returns how many were deleted.
+*/
+func (s *Sweeper) Sweep() (count int, err error) {
+	return s.sweep(nil)
+}
+
+/*
+sweep deletes expired keys in batches until there are none left or the stop channel is closed.
+*/
+func (s *Sweeper) sweep(stop <-chan struct{}) (count int, err error) {
+	for {
+		var n int
+		if n, err = s.sweepBatch(); err != nil {
+			return
+		}
+		count += n
+		if n < s.batchSize {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+func (s *Sweeper) sweepBatch() (count int, err error) {
+	txnFunc := func(t *BucketTx) (err error) {
+		var queueKeys [][]byte
+		bf := func(b *bolt.Bucket) error {
+			queueKeys = expiredQueueKeys(b, s.batchSize)
+			return nil
+		}
+		if err = t.View(bf); err != nil {
+			return
+		}
+		for _, qk := range queueKeys {
+			if err = t.ForByteKey(qk[8:]).Delete(); err != nil {
+				return
+			}
+		}
+		// the queue entries are normally removed with the keys; this ensures the sweep progresses if they were not
+		bf = func(b *bolt.Bucket) (err error) {
+			if queue := ttlQueueBucket(b); queue != nil {
+				for _, qk := range queueKeys {
+					if err = queue.Delete(qk); err != nil {
+						return
+					}
+				}
+			}
+			return
+		}
+		if err = t.Update(bf); err != nil {
+			return
+		}
+		count = len(queueKeys)
+		return
+	}
+	err = s.bb.Txn(txnFunc)
+	return
+}
+
+/*
+expiredQueueKeys returns copies of up to limit expiry queue entries which have expired, earliest first. Each entry is the 8-byte expiry time followed by the key.
+*/
+func expiredQueueKeys(b *bolt.Bucket, limit int) (queueKeys [][]byte) {
+	queue := ttlQueueBucket(b)
+	if queue == nil {
+		return
+	}
+	end := NewInt64Key(timeNow().UnixNano()).KeyBytes()
+	c := queue.Cursor()
+	for k, _ := c.First(); k != nil && len(queueKeys) < limit; k, _ = c.Next() {
+		if len(k) < 8 || bytes.Compare(k[:8], end) > 0 {
+			break
+		}
+		queueKeys = append(queueKeys, append([]byte{}, k...))
+	}
+	return
+}
+
+/*
+Start starts a goroutine which sweeps the bucket every interval until Stop is called. Calling Start on a running Sweeper has no effect.
+*/
+func (s *Sweeper) Start() {
+	tickFunc := func(stop <-chan struct{}) {
+		count, err := s.sweep(stop)
+		if s.OnSweep != nil {
+			s.OnSweep(count, err)
+		}
+	}
+	s.loop.start(tickFunc)
+}
+
+/*
+Stop stops the goroutine started by Start and waits for any sweep in progress to finish.
+*/
+func (s *Sweeper) Stop() {
+	s.loop.halt()
+}
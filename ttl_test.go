@@ -0,0 +1,209 @@
+package bucketeer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTTL(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	sessions := New(db, "sessions")
+	sessions.EnsurePathBuckets()
+	sessions.ForStringKey("s1").PutWithTTL([]byte("alice"), time.Minute)
+	sessions.ForStringKey("s2").PutWithTTL([]byte("bob"), time.Hour)
+	sessions.ForStringKey("s3").PutWithTTL([]byte("carol"), time.Minute)
+	sessions.ForStringKey("s4").PutStringValue("dave")
+
+	var ttl time.Duration
+	var ok bool
+	if ttl, ok, err = sessions.ForStringKey("s1").TTL(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !ok || ttl != time.Minute {
+		t.Fatalf("Expected a TTL of %v, got %v\n", time.Minute, ttl)
+	}
+
+	// writing a new value without a TTL makes the key persistent
+	sessions.ForStringKey("s3").PutStringValue("carol2")
+	if _, ok, _ = sessions.ForStringKey("s3").TTL(); ok {
+		t.Fatal("Expected s3 to no longer expire")
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	var v []byte
+	if v, err = sessions.ForStringKey("s1").GetByteValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != nil {
+		t.Fatalf("Expected an expired value to be absent, got %s\n", v)
+	}
+	if _, err = sessions.WithStrict(true).ForStringKey("s1").GetByteValue(); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected %v, got %v\n", ErrKeyNotFound, err)
+	}
+	if exists, _ := sessions.ForStringKey("s1").Exists(); exists {
+		t.Fatal("Expected an expired key not to exist")
+	}
+	var keys []string
+	iterFunc := func(c *Cursor) error {
+		keys = append(keys, string(c.StringKey()))
+		return nil
+	}
+	sessions.Iterate(iterFunc)
+	if len(keys) != 3 || keys[0] != "s2" {
+		t.Fatalf("Expected [s2 s3 s4], got %v\n", keys)
+	}
+
+	// a conditional write sees the expired key as absent
+	if err = sessions.ForStringKey("s1").PutIfAbsent([]byte("eve")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ = sessions.ForStringKey("s1").GetByteValue(); string(v) != "eve" {
+		t.Fatalf("Expected eve, got %s\n", v)
+	}
+	sessions.ForStringKey("s1").PutWithTTL([]byte("eve"), time.Minute)
+
+	now = now.Add(2 * time.Hour)
+	sweeper := NewSweeper(sessions, time.Millisecond, 1)
+	var count int
+	if count, err = sweeper.Sweep(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 keys to be swept, got %d\n", count)
+	}
+	keys = nil
+	sessions.Iterate(iterFunc)
+	if len(keys) != 2 || keys[0] != "s3" {
+		t.Fatalf("Expected [s3 s4], got %v\n", keys)
+	}
+	sessions.View(func(b *bolt.Bucket) error {
+		if n := ttlBucket(b).Stats().KeyN; n != 0 {
+			t.Fatalf("Expected no expiry entries, got %d\n", n)
+		}
+		return nil
+	})
+
+	sessions.ForStringKey("s5").PutWithTTL([]byte("frank"), time.Minute)
+	now = now.Add(2 * time.Minute)
+	swept := make(chan int, 1)
+	sweeper.OnSweep = func(count int, err error) {
+		if count > 0 {
+			select {
+			case swept <- count:
+			default:
+			}
+		}
+	}
+	sweeper.Start()
+	select {
+	case count = <-swept:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a sweep from the background goroutine")
+	}
+	sweeper.Stop()
+	if count != 1 {
+		t.Fatalf("Expected 1 key to be swept, got %d\n", count)
+	}
+}
+
+func TestTTLDelete(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	b.ForStringKey("a1").PutWithTTL([]byte("expired"), time.Minute)
+	b.ForStringKey("a2").PutWithTTL([]byte("live"), time.Hour)
+	b.ForStringKey("a3").PutStringValue("persistent")
+	b.ForStringKey("b1").PutWithTTL([]byte("expired"), time.Minute)
+	now = now.Add(2 * time.Minute)
+
+	// only the live keys are counted, but the expired key and its expiry are removed too
+	var count int
+	if count, err = b.DeletePrefix(NewStringKey("a")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 keys to be deleted, got %d\n", count)
+	}
+	if count, err = b.DeleteRange(NewStringKey("b"), nil, ScanOptions{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 0 {
+		t.Fatalf("Expected no keys to be counted, got %d\n", count)
+	}
+	b.View(func(b *bolt.Bucket) error {
+		if n := b.Stats().KeyN - b.Bucket(metaBucketName).Stats().KeyN; n != 1 {
+			t.Fatalf("Expected only the meta bucket to remain, got %d entries\n", n)
+		}
+		if n := ttlBucket(b).Stats().KeyN + ttlQueueBucket(b).Stats().KeyN; n != 0 {
+			t.Fatalf("Expected no expiry entries, got %d\n", n)
+		}
+		return nil
+	})
+
+	// the limit of a range delete only counts keys which have not expired
+	b.ForStringKey("c1").PutWithTTL([]byte("expired"), time.Minute)
+	b.ForStringKey("c2").PutWithTTL([]byte("expired"), time.Minute)
+	now = now.Add(2 * time.Minute)
+	b.ForStringKey("c3").PutStringValue("live")
+	b.ForStringKey("c4").PutStringValue("live")
+	if count, err = b.DeleteRange(NewStringKey("c"), nil, ScanOptions{Limit: 1}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 key to be deleted, got %d\n", count)
+	}
+	var keys []string
+	iterFunc := func(c *Cursor) error {
+		keys = append(keys, string(c.StringKey()))
+		return nil
+	}
+	b.Iterate(iterFunc)
+	if len(keys) != 1 || keys[0] != "c4" {
+		t.Fatalf("Expected [c4], got %v\n", keys)
+	}
+
+	// a missing key is read as before, while an expired key is skipped
+	if _, err = b.ForStringKey("missing").GetInt64Value(); err == nil {
+		t.Fatal("Expected an error reading a missing key as an int64")
+	}
+	b.ForStringKey("d1").PutWithTTL([]byte{0, 0, 0, 0, 0, 0, 0, 5}, time.Minute)
+	now = now.Add(2 * time.Minute)
+	var n int64
+	if n, err = b.ForStringKey("d1").GetInt64Value(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if n != 0 {
+		t.Fatalf("Expected an expired key to read as 0, got %d\n", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic for a zero interval")
+		}
+	}()
+	NewSweeper(b, 0, 1)
+}
@@ -0,0 +1,233 @@
+package bucketeer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	tupleBytes  byte = 0x01
+	tupleString byte = 0x02
+	tupleInt64  byte = 0x10
+	tupleUint64 byte = 0x11
+	tupleBool   byte = 0x20
+	tupleTime   byte = 0x30
+)
+
+/*
+TupleKey is a composite key made of a sequence of components. Each component is encoded with a type tag in a self-delimiting, byte-order-preserving form, so keys sort by their first component, then their second, and so on. A TupleKey with fewer components can be used with ScanPrefix to visit every longer TupleKey starting with the same components.
+
+Supported component types are []byte, string, bool, time.Time, and the signed and unsigned integer types. Signed integers are decoded as int64 and unsigned integers as uint64; the two do not sort relative to each other, so a given component position should always hold the same type. Times are decoded in UTC.
+*/
+type TupleKey []interface{}
+
+/*
+NewTupleKey creates a TupleKey from the provided components.
+*/
+func NewTupleKey(parts ...interface{}) TupleKey {
+	return TupleKey(parts)
+}
+
+/*
+KeyBytes encodes the TupleKey. If a component has an unsupported type, this function will panic.
+*/
+func (k TupleKey) KeyBytes() (b []byte) {
+	var err error
+	if b, err = k.EncodeKey(); err != nil {
+		panic(err.Error())
+	}
+	return
+}
+
+/*
+EncodeKey encodes the TupleKey, returning an error if a component has an unsupported type.
+*/
+func (k TupleKey) EncodeKey() (b []byte, err error) {
+	var buf bytes.Buffer
+	for _, part := range k {
+		if err = encodeTuplePart(&buf, part); err != nil {
+			return
+		}
+	}
+	b = buf.Bytes()
+	return
+}
+
+func encodeTuplePart(buf *bytes.Buffer, part interface{}) (err error) {
+	switch v := part.(type) {
+	case []byte:
+		buf.WriteByte(tupleBytes)
+		writeTupleBytes(buf, v)
+	case string:
+		buf.WriteByte(tupleString)
+		writeTupleBytes(buf, []byte(v))
+	case bool:
+		buf.WriteByte(tupleBool)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case time.Time:
+		buf.WriteByte(tupleTime)
+		buf.Write(NewInt64Key(v.Unix()).KeyBytes())
+		var nanos [4]byte
+		binary.BigEndian.PutUint32(nanos[:], uint32(v.Nanosecond()))
+		buf.Write(nanos[:])
+	case int:
+		writeTupleInt64(buf, int64(v))
+	case int8:
+		writeTupleInt64(buf, int64(v))
+	case int16:
+		writeTupleInt64(buf, int64(v))
+	case int32:
+		writeTupleInt64(buf, int64(v))
+	case int64:
+		writeTupleInt64(buf, v)
+	case uint:
+		writeTupleUint64(buf, uint64(v))
+	case uint8:
+		writeTupleUint64(buf, uint64(v))
+	case uint16:
+		writeTupleUint64(buf, uint64(v))
+	case uint32:
+		writeTupleUint64(buf, uint64(v))
+	case uint64:
+		writeTupleUint64(buf, v)
+	default:
+		err = fmt.Errorf("Unsupported tuple component type %T", part)
+	}
+	return
+}
+
+/*
+writeTupleBytes writes the provided bytes with each 0x00 escaped as 0x00 0xFF, followed by a 0x00 terminator.
+*/
+func writeTupleBytes(buf *bytes.Buffer, b []byte) {
+	for _, c := range b {
+		buf.WriteByte(c)
+		if c == 0x00 {
+			buf.WriteByte(0xff)
+		}
+	}
+	buf.WriteByte(0x00)
+}
+
+/*
+tuplePrefixEnd returns the smallest key which is greater than every TupleKey starting with the components encoded in the provided prefix, or nil if the prefix is empty. A whole component is followed either by nothing or by a type tag, while a byte string component which continues past the prefix's last component has an escaped 0x00 followed by 0xFF, so appending 0xFF excludes it.
+*/
+func tuplePrefixEnd(prefix []byte) (end []byte) {
+	if len(prefix) == 0 {
+		return
+	}
+	end = append(append(end, prefix...), 0xff)
+	return
+}
+
+func writeTupleInt64(buf *bytes.Buffer, v int64) {
+	buf.WriteByte(tupleInt64)
+	buf.Write(NewInt64Key(v).KeyBytes())
+}
+
+func writeTupleUint64(buf *bytes.Buffer, v uint64) {
+	buf.WriteByte(tupleUint64)
+	buf.Write(NewUint64Key(v).KeyBytes())
+}
+
+/*
+DecodeTupleKey decodes the byte form of a TupleKey back into its components.
+*/
+func DecodeTupleKey(b []byte) (k TupleKey, err error) {
+	k = TupleKey{}
+	for len(b) != 0 {
+		tag := b[0]
+		b = b[1:]
+		switch tag {
+		case tupleBytes, tupleString:
+			var v []byte
+			if v, b, err = readTupleBytes(b); err != nil {
+				return
+			}
+			if tag == tupleString {
+				k = append(k, string(v))
+			} else {
+				k = append(k, v)
+			}
+		case tupleBool:
+			if len(b) < 1 {
+				err = errors.New("Truncated tuple bool component")
+				return
+			}
+			k = append(k, b[0] != 0)
+			b = b[1:]
+		case tupleTime:
+			if len(b) < 12 {
+				err = errors.New("Truncated tuple time component")
+				return
+			}
+			var sec Int64Key
+			if sec, err = DecodeInt64Key(b[:8]); err != nil {
+				return
+			}
+			nsec := binary.BigEndian.Uint32(b[8:12])
+			k = append(k, time.Unix(int64(sec), int64(nsec)).UTC())
+			b = b[12:]
+		case tupleInt64:
+			if len(b) < 8 {
+				err = errors.New("Truncated tuple int64 component")
+				return
+			}
+			var v Int64Key
+			if v, err = DecodeInt64Key(b[:8]); err != nil {
+				return
+			}
+			k = append(k, int64(v))
+			b = b[8:]
+		case tupleUint64:
+			if len(b) < 8 {
+				err = errors.New("Truncated tuple uint64 component")
+				return
+			}
+			var v Uint64Key
+			if v, err = DecodeUint64Key(b[:8]); err != nil {
+				return
+			}
+			k = append(k, uint64(v))
+			b = b[8:]
+		default:
+			err = fmt.Errorf("Unknown tuple component tag 0x%02x", tag)
+			return
+		}
+	}
+	return
+}
+
+func (k *TupleKey) DecodeKey(b []byte) (err error) {
+	*k, err = DecodeTupleKey(b)
+	return
+}
+
+/*
+readTupleBytes reads an escaped, terminated byte string and returns it along with the remaining bytes.
+*/
+func readTupleBytes(b []byte) (v []byte, rest []byte, err error) {
+	v = []byte{}
+	for i := 0; i < len(b); i++ {
+		if b[i] != 0x00 {
+			v = append(v, b[i])
+			continue
+		}
+		if i+1 < len(b) && b[i+1] == 0xff {
+			v = append(v, 0x00)
+			i++
+			continue
+		}
+		rest = b[i+1:]
+		return
+	}
+	err = errors.New("Unterminated tuple byte string component")
+	return
+}
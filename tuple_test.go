@@ -0,0 +1,92 @@
+package bucketeer
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTupleKeyOrder(t *testing.T) {
+
+	t0 := time.Date(2012, time.January, 1, 0, 0, 0, 0, time.UTC)
+	kbs := [][]byte{
+		NewTupleKey(uint64(1), int64(math.MinInt64), "").KeyBytes(),
+		NewTupleKey(uint64(1), int64(-1), "").KeyBytes(),
+		NewTupleKey(uint64(1), int64(-1), "a").KeyBytes(),
+		NewTupleKey(uint64(1), int64(-1), "a\x00").KeyBytes(),
+		NewTupleKey(uint64(1), int64(-1), "a\x00b").KeyBytes(),
+		NewTupleKey(uint64(1), int64(-1), "ab").KeyBytes(),
+		NewTupleKey(uint64(1), int64(0), "").KeyBytes(),
+		NewTupleKey(uint64(2), t0.Add(-time.Nanosecond)).KeyBytes(),
+		NewTupleKey(uint64(2), t0).KeyBytes(),
+		NewTupleKey(uint64(2), t0.Add(time.Second)).KeyBytes(),
+	}
+	for i, kb := range kbs[:len(kbs)-1] {
+		if bytes.Compare(kb, kbs[i+1]) != -1 {
+			t.Fatalf("Expected %v to be before %v\n", kb, kbs[i+1])
+		}
+	}
+}
+
+func TestTupleKeyRoundTrip(t *testing.T) {
+
+	t0 := time.Date(2012, time.January, 1, 0, 0, 0, 5, time.UTC)
+	expected := NewTupleKey(uint64(42), int64(-7), "a\x00b", []byte{0, 255}, true, t0)
+
+	k, err := DecodeTupleKey(expected.KeyBytes())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(expected, k) {
+		t.Fatalf("Expected %v, got %v\n", expected, k)
+	}
+
+	if _, err = NewTupleKey(1.5).EncodeKey(); err == nil {
+		t.Fatal("Expected an error encoding a float64 component")
+	}
+}
+
+func TestTupleKeyPrefixScan(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	b.ForTupleKey(uint64(1), int64(10), "x").PutStringValue("1-10-x")
+	b.ForTupleKey(uint64(2), int64(-5), "y").PutStringValue("2-(-5)-y")
+	b.ForTupleKey(uint64(2), int64(10), "z").PutStringValue("2-10-z")
+	b.ForTupleKey(uint64(3), int64(0), "w").PutStringValue("3-0-w")
+
+	var values []string
+	scanFunc := func(c *Cursor) error {
+		values = append(values, c.StringValue())
+		return nil
+	}
+	if err = b.ScanPrefix(NewTupleKey(uint64(2)), ScanOptions{}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []string{"2-(-5)-y", "2-10-z"}
+	if !reflect.DeepEqual(expected, values) {
+		t.Fatalf("Expected %v, got %v\n", expected, values)
+	}
+
+	// a component followed by a zero byte is not a prefix of the shorter component
+	b.ForTupleKey("a", int64(1)).PutStringValue("a-1")
+	b.ForTupleKey("a\x00b", int64(2)).PutStringValue("a0b-2")
+	values = nil
+	if err = b.ScanPrefix(NewTupleKey("a"), ScanOptions{}, scanFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if expected = []string{"a-1"}; !reflect.DeepEqual(expected, values) {
+		t.Fatalf("Expected %v, got %v\n", expected, values)
+	}
+}
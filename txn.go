@@ -0,0 +1,62 @@
+package bucketeer
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+/*
+BucketTx is a Bucketeer bound to a single bolt transaction. Every operation made through it, or through the Keyfarers, Cursors and Stores created from it, shares that transaction, so the operations are committed or rolled back together. A BucketTx is only valid within the scope of the function it was passed to.
+*/
+type BucketTx struct {
+	*Bucketeer
+}
+
+/*
+Txn executes the provided function in an Update transaction. If the function returns an error, none of the operations made through the BucketTx are committed. If this Bucketeer already belongs to a BucketTx, that transaction is reused.
+*/
+func (bb *Bucketeer) Txn(txnFunc func(t *BucketTx) error) error {
+	txf := func(tx *bolt.Tx) error {
+		return txnFunc(bb.inTx(tx))
+	}
+	return bb.updateTx(txf)
+}
+
+/*
+ViewTxn executes the provided function in a View transaction. Write operations made through the BucketTx will fail.
+*/
+func (bb *Bucketeer) ViewTxn(txnFunc func(t *BucketTx) error) error {
+	txf := func(tx *bolt.Tx) error {
+		return txnFunc(bb.inTx(tx))
+	}
+	return bb.viewTx(txf)
+}
+
+func (bb *Bucketeer) inTx(tx *bolt.Tx) *BucketTx {
+	nb := *bb
+	nb.tx = tx
+	return &BucketTx{&nb}
+}
+
+/*
+In creates a BucketTx for another Bucketeer's path which shares this transaction. The other Bucketeer must use the same database; otherwise this function will panic.
+*/
+func (t *BucketTx) In(bb *Bucketeer) *BucketTx {
+	if bb.db != t.db {
+		panic("Bucketeer must use the same database as the transaction")
+	}
+	return bb.inTx(t.tx)
+}
+
+/*
+InPath creates a BucketTx for the provided bucket path which shares this transaction.
+*/
+func (t *BucketTx) InPath(path Path) *BucketTx {
+	return t.In(ForPath(t.db, path))
+}
+
+/*
+Tx returns the underlying bolt transaction.
+*/
+func (t *BucketTx) Tx() *bolt.Tx {
+	return t.tx
+}
@@ -0,0 +1,148 @@
+package bucketeer
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTxn(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	orders := New(db, "orders")
+	orders.EnsurePathBuckets()
+	counters := New(db, "counters")
+	counters.EnsurePathBuckets()
+	counters.ForStringKey("orders").PutByteValue(make([]byte, 8))
+
+	txnFunc := func(tx *BucketTx) (err error) {
+		if err = tx.ForUint64Key(1).PutJsonValue(map[string]string{"item": "widget"}); err != nil {
+			return
+		}
+		_, err = tx.In(counters).ForStringKey("orders").IncrementInt64Value(1)
+		return
+	}
+	if err = orders.Txn(txnFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var count int64
+	if count, err = counters.ForStringKey("orders").IncrementInt64Value(0); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1, got %d\n", count)
+	}
+
+	failure := errors.New("failure")
+	txnFunc = func(tx *BucketTx) (err error) {
+		if err = tx.ForUint64Key(2).PutJsonValue(map[string]string{"item": "gadget"}); err != nil {
+			return
+		}
+		if _, err = tx.In(counters).ForStringKey("orders").IncrementInt64Value(1); err != nil {
+			return
+		}
+		return failure
+	}
+	if err = orders.Txn(txnFunc); err != failure {
+		t.Fatalf("Expected %v, got %v\n", failure, err)
+	}
+
+	if count, err = counters.ForStringKey("orders").IncrementInt64Value(0); err != nil {
+		t.Fatal(err.Error())
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 after rollback, got %d\n", count)
+	}
+	var v []byte
+	if v, err = orders.ForUint64Key(2).GetByteValue(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if v != nil {
+		t.Fatalf("Expected no value after rollback, got %s\n", v)
+	}
+}
+
+func TestBatch(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test").WithBatch(true)
+	b.EnsurePathBuckets()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := uint64(0); i < 20; i++ {
+		wg.Add(1)
+		go func(i uint64) {
+			defer wg.Done()
+			errs <- b.ForUint64Key(i).PutUvarintValue(i)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err = range errs {
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	stats, err := b.GetBucketStats()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if stats.KeyN != 20 {
+		t.Fatalf("Expected 20 keys, got %d\n", stats.KeyN)
+	}
+}
+
+func TestIncrementMany(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	b.ForStringKey("max").PutInt64Value(math.MaxInt64)
+
+	deltas := []KeyDelta{
+		{Key: NewStringKey("a"), Delta: 2},
+		{Key: NewByteKey([]byte("b")), Delta: -3},
+		{Key: NewTupleKey("c", int64(1)), Delta: 5},
+	}
+	newValues, err := b.IncrementMany(deltas)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if expected := []int64{2, -3, 5}; !reflect.DeepEqual(expected, newValues) {
+		t.Fatalf("Expected %v, got %v\n", expected, newValues)
+	}
+
+	deltas = append(deltas, KeyDelta{Key: NewStringKey("max"), Delta: 1})
+	if _, err = b.IncrementMany(deltas); err != ErrOverflow {
+		t.Fatalf("Expected ErrOverflow, got %v\n", err)
+	}
+	var a int64
+	if a, err = b.ForStringKey("a").GetInt64Value(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if a != 2 {
+		t.Fatalf("Expected 2 after rollback, got %d\n", a)
+	}
+}
@@ -0,0 +1,152 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+ErrSkipBucket can be returned from a WalkFunc to skip the nested buckets of the current bucket. The walk continues with the current bucket's siblings.
+*/
+var ErrSkipBucket = errors.New("Skip this bucket")
+
+/*
+WalkFunc is called for each bucket visited by Walk, with the bucket's full path, its stats, and its depth relative to the root of the walk. The stats include the bucket's nested buckets, as GetBucketStats reports them. The path is only valid within the scope of the function. Returning ErrSkipBucket skips the bucket's nested buckets, and returning ErrStopIteration ends the walk without an error.
+*/
+type WalkFunc func(path Path, stats bolt.BucketStats, depth int) error
+
+/*
+Walk visits the bucket at the root path and every bucket nested beneath it, depth-first and in key order, except for the nested buckets named MetaBucketName. The root bucket has depth 0. If the root path is empty, every top-level bucket in the database is visited with depth 1.
+*/
+func Walk(db *bolt.DB, root Path, walkFunc WalkFunc) error {
+	return WalkDepth(db, root, -1, walkFunc)
+}
+
+/*
+WalkDepth is like Walk, but does not visit buckets deeper than the provided maximum depth. A negative maximum depth means there is no limit.
+*/
+func WalkDepth(db *bolt.DB, root Path, maxDepth int, walkFunc WalkFunc) error {
+	txf := func(tx *bolt.Tx) error {
+		return WalkTx(tx, root, maxDepth, walkFunc)
+	}
+	return db.View(txf)
+}
+
+/*
+Walk visits the current bucket and every bucket nested beneath it, depth-first and in key order.
+*/
+func (bb *Bucketeer) Walk(walkFunc WalkFunc) error {
+	return bb.WalkDepth(-1, walkFunc)
+}
+
+/*
+WalkDepth is like Walk, but does not visit buckets deeper than the provided maximum depth relative to the current bucket.
+*/
+func (bb *Bucketeer) WalkDepth(maxDepth int, walkFunc WalkFunc) error {
+	txf := func(tx *bolt.Tx) error {
+		return WalkTx(tx, bb.path, maxDepth, walkFunc)
+	}
+	return bb.viewTx(txf)
+}
+
+/*
+WalkTx walks the buckets beneath the root path within an existing transaction. If the root bucket does not exist, a BucketNotFoundError is returned.
+*/
+func WalkTx(tx *bolt.Tx, root Path, maxDepth int, walkFunc WalkFunc) (err error) {
+	if len(root) == 0 {
+		err = walkTopLevel(tx, maxDepth, walkFunc)
+	} else {
+		var b *bolt.Bucket
+		if b, err = RequireBucket(tx, root); err != nil {
+			return
+		}
+		err = walkBucket(b, root, 0, maxDepth, walkFunc)
+	}
+	if err == ErrStopIteration {
+		err = nil
+	}
+	return
+}
+
+func walkTopLevel(tx *bolt.Tx, maxDepth int, walkFunc WalkFunc) error {
+	if maxDepth == 0 {
+		return nil
+	}
+	c := tx.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if err := walkBucket(tx.Bucket(k), nestPath(nil, k), 1, maxDepth, walkFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkBucket(b *bolt.Bucket, path Path, depth int, maxDepth int, walkFunc WalkFunc) error {
+	if err := walkFunc(path, bucketStats(b), depth); err == ErrSkipBucket {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if maxDepth >= 0 && depth >= maxDepth {
+		return nil
+	}
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v != nil || bytes.Equal(k, metaBucketName) {
+			continue
+		}
+		if err := walkBucket(b.Bucket(k), nestPath(path, k), depth+1, maxDepth, walkFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+bucketStats returns the bucket's stats, excluding the nested buckets named MetaBucketName beneath it. Their keys, buckets and pages are excluded, but the space their entries take in their parents' pages is not, and Depth is left as bolt reports it.
+*/
+func bucketStats(b *bolt.Bucket) (stats bolt.BucketStats) {
+	stats = b.Stats()
+	excludeMetaStats(b, &stats)
+	return
+}
+
+func excludeMetaStats(b *bolt.Bucket, stats *bolt.BucketStats) {
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v != nil {
+			continue
+		}
+		if !bytes.Equal(k, metaBucketName) {
+			excludeMetaStats(b.Bucket(k), stats)
+			continue
+		}
+		m := b.Bucket(k).Stats()
+		// the parent's leaf page also counts the meta bucket's own entry
+		stats.KeyN -= m.KeyN + 1
+		stats.BranchPageN -= m.BranchPageN
+		stats.BranchOverflowN -= m.BranchOverflowN
+		stats.LeafPageN -= m.LeafPageN
+		stats.LeafOverflowN -= m.LeafOverflowN
+		stats.BranchAlloc -= m.BranchAlloc
+		stats.BranchInuse -= m.BranchInuse
+		stats.LeafAlloc -= m.LeafAlloc
+		stats.LeafInuse -= m.LeafInuse
+		stats.BucketN -= m.BucketN
+		stats.InlineBucketN -= m.InlineBucketN
+		stats.InlineBucketInuse -= m.InlineBucketInuse
+	}
+}
+
+/*
+nestPath allocates a new Path with a copy of the provided bucket name appended to the provided path.
+*/
+func nestPath(p Path, bucket []byte) (newPath Path) {
+	newPath = make(Path, len(p)+1)
+	copy(newPath, p)
+	newPath[len(p)] = make([]byte, len(bucket))
+	copy(newPath[len(p)], bucket)
+	return
+}
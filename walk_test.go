@@ -0,0 +1,72 @@
+package bucketeer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestWalk(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	EnsurePathBuckets(db, NewPath("a", "b", "c"))
+	EnsurePathBuckets(db, NewPath("a", "d"))
+	EnsurePathBuckets(db, NewPath("e"))
+	New(db, "a", "b").ForStringKey("k1").PutStringValue("v1")
+
+	var visited []string
+	walkFunc := func(path Path, stats bolt.BucketStats, depth int) error {
+		visited = append(visited, path.String())
+		return nil
+	}
+
+	if err = Walk(db, nil, walkFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected := []string{"[a]", "[a, b]", "[a, b, c]", "[a, d]", "[e]"}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected %v, got %v\n", expected, visited)
+	}
+
+	visited = nil
+	if err = New(db, "a").WalkDepth(1, walkFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	expected = []string{"[a]", "[a, b]", "[a, d]"}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected %v, got %v\n", expected, visited)
+	}
+
+	visited = nil
+	skipFunc := func(path Path, stats bolt.BucketStats, depth int) error {
+		visited = append(visited, path.String())
+		if depth == 1 {
+			return ErrSkipBucket
+		}
+		return nil
+	}
+	if err = New(db, "a").Walk(skipFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected %v, got %v\n", expected, visited)
+	}
+
+	var keyN int
+	statsFunc := func(path Path, stats bolt.BucketStats, depth int) error {
+		keyN = stats.KeyN
+		return ErrStopIteration
+	}
+	if err = New(db, "a", "b").Walk(statsFunc); err != nil {
+		t.Fatal(err.Error())
+	}
+	if keyN != 2 {
+		t.Fatalf("Expected 2 keys (one value and one nested bucket), got %d\n", keyN)
+	}
+}
@@ -0,0 +1,157 @@
+package bucketeer
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+/*
+DefaultWatchBuffer is the number of events buffered for each watcher created with Watch.
+*/
+const DefaultWatchBuffer = 64
+
+/*
+EventType describes the change made to a key.
+*/
+type EventType int
+
+const (
+	// EventPut is a key which was created or whose value changed.
+	EventPut EventType = iota
+	// EventDelete is a key which was deleted.
+	EventDelete
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventPut:
+		return "put"
+	case EventDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+/*
+Event describes a change to a key in a watched bucket. Key and Value are copies; Value is the new value for a put and nil for a delete. Dropped is the number of events which were dropped for this watcher since the previous event was delivered.
+*/
+type Event struct {
+	Type    EventType
+	Path    Path
+	Key     []byte
+	Value   []byte
+	Dropped int
+}
+
+type watcher struct {
+	prefix  []byte
+	mu      sync.Mutex
+	ch      chan Event
+	dropped int
+	closed  bool
+}
+
+/*
+Watch returns a channel which receives an Event for each change to a key in the bucket starting with the bytes of the provided Key, and a function which stops the watch and closes the channel. A nil Key watches every key. If the Key cannot be encoded, an error is returned.
+
+Events are delivered after the transaction which made the change commits, and never for transactions which are rolled back. The events of a single transaction are delivered in the order its changes were made, but bolt runs commit handlers after releasing its writer lock, so the events of concurrent transactions may interleave. Only writes made through a Keyfarer, Store or BucketTx for this database and path are seen; writes made directly to a bolt.Bucket are not. Writes which leave a value unchanged do not produce events. An expired key produces a delete event when it is removed, by a Sweeper or by the next write to it, rather than at the moment it expires.
+
+Delivery never blocks writers. The channel buffers DefaultWatchBuffer events; when it is full, new events are dropped, and the next event delivered reports how many were dropped in its Dropped field. A watcher which sees dropped events should re-read the keys it cares about.
+*/
+func (bb *Bucketeer) Watch(prefix Key) (<-chan Event, func(), error) {
+	return bb.WatchBuffer(prefix, DefaultWatchBuffer)
+}
+
+/*
+WatchBuffer is like Watch, but buffers the provided number of events, which must be at least 1.
+*/
+func (bb *Bucketeer) WatchBuffer(prefix Key, size int) (events <-chan Event, cancel func(), err error) {
+	if size < 1 {
+		err = errors.New("Watch buffer size must be at least 1")
+		return
+	}
+	var prefixBytes []byte
+	if prefixBytes, err = encodeBound(prefix); err != nil {
+		return
+	}
+	w := &watcher{
+		prefix: prefixBytes,
+		ch:     make(chan Event, size),
+	}
+	db, path := bb.db, bb.path
+	modifyHooks(db, path, func(h *pathHooks) {
+		h.watchers = append(append([]*watcher{}, h.watchers...), w)
+	})
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			modifyHooks(db, path, func(h *pathHooks) {
+				watchers := make([]*watcher, 0, len(h.watchers))
+				for _, existing := range h.watchers {
+					if existing != w {
+						watchers = append(watchers, existing)
+					}
+				}
+				h.watchers = watchers
+			})
+			w.close()
+		})
+	}
+	events = w.ch
+	return
+}
+
+/*
+notifyWatchers queues an event for each watcher of the key, to be delivered when the transaction commits.
+*/
+func notifyWatchers(tx *bolt.Tx, watchers []*watcher, path Path, key []byte, newValue []byte) {
+	var matched []*watcher
+	for _, w := range watchers {
+		if bytes.HasPrefix(key, w.prefix) {
+			matched = append(matched, w)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+	e := Event{
+		Type: EventPut,
+		Path: path,
+		Key:  append([]byte{}, key...),
+	}
+	if newValue == nil {
+		e.Type = EventDelete
+	} else {
+		e.Value = append([]byte{}, newValue...)
+	}
+	tx.OnCommit(func() {
+		for _, w := range matched {
+			w.send(e)
+		}
+	})
+}
+
+func (w *watcher) send(e Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	e.Dropped = w.dropped
+	select {
+	case w.ch <- e:
+		w.dropped = 0
+	default:
+		w.dropped++
+	}
+}
+
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	close(w.ch)
+}
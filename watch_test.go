@@ -0,0 +1,150 @@
+package bucketeer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestWatch(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	events, cancel, err := b.Watch(NewStringKey("user:"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b.ForStringKey("user:1").PutStringValue("alice")
+	b.ForStringKey("other").PutStringValue("ignored")
+	b.ForStringKey("user:1").PutStringValue("alice")
+	New(db, "test").ForStringKey("user:1").Delete()
+
+	failure := errors.New("failure")
+	txnFunc := func(t *BucketTx) error {
+		t.ForStringKey("user:2").PutStringValue("rolled back")
+		return failure
+	}
+	b.Txn(txnFunc)
+
+	expected := []Event{
+		{Type: EventPut, Key: []byte("user:1"), Value: []byte("alice")},
+		{Type: EventDelete, Key: []byte("user:1")},
+	}
+	for _, e := range expected {
+		select {
+		case actual := <-events:
+			if actual.Type != e.Type || string(actual.Key) != string(e.Key) || string(actual.Value) != string(e.Value) {
+				t.Fatalf("Expected %s %s=%s, got %s %s=%s\n", e.Type, e.Key, e.Value, actual.Type, actual.Key, actual.Value)
+			}
+			if actual.Path.String() != "[test]" {
+				t.Fatalf("Expected [test], got %s\n", actual.Path.String())
+			}
+		default:
+			t.Fatalf("Expected %s event\n", e.Type)
+		}
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("Expected no more events, got %s %s\n", e.Type, e.Key)
+	default:
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Fatal("Expected the channel to be closed")
+	}
+	if h := lookupHooks(db, NewPath("test")); h != nil {
+		t.Fatal("Expected no hooks after cancelling")
+	}
+}
+
+func TestWatchDropped(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+
+	events, cancel, err := b.WatchBuffer(nil, 2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer cancel()
+	for i := int64(0); i < 5; i++ {
+		b.ForInt64Key(i).PutVarintValue(i)
+	}
+	<-events
+	<-events
+	b.ForInt64Key(5).PutVarintValue(5)
+	e := <-events
+	if e.Dropped != 3 {
+		t.Fatalf("Expected 3 dropped events, got %d\n", e.Dropped)
+	}
+	if k, _ := DecodeInt64Key(e.Key); k != 5 {
+		t.Fatalf("Expected key 5, got %d\n", k)
+	}
+
+	for _, size := range []int{0, -1} {
+		if _, _, err = b.WatchBuffer(nil, size); err == nil {
+			t.Fatalf("Expected an error for a buffer size of %d\n", size)
+		}
+	}
+	if _, _, err = b.Watch(NewTupleKey(1.5)); err == nil {
+		t.Fatal("Expected an error for a prefix which cannot be encoded")
+	}
+	if h := lookupHooks(db, NewPath("test")); h == nil || len(h.watchers) != 1 {
+		t.Fatal("Expected only the first watcher to be registered")
+	}
+}
+
+func TestWatchExpiry(t *testing.T) {
+
+	db, err := bolt.Open(tempfile(), 0666, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	b := New(db, "test")
+	b.EnsurePathBuckets()
+	b.ForStringKey("s1").PutWithTTL([]byte("alice"), time.Minute)
+	b.ForStringKey("s2").PutWithTTL([]byte("bob"), time.Minute)
+
+	events, cancel, err := b.Watch(nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer cancel()
+	now = now.Add(2 * time.Minute)
+	if _, err = NewSweeper(b, time.Minute, 10).Sweep(); err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, key := range []string{"s1", "s2"} {
+		select {
+		case e := <-events:
+			if e.Type != EventDelete || string(e.Key) != key {
+				t.Fatalf("Expected delete %s, got %s %s\n", key, e.Type, e.Key)
+			}
+		default:
+			t.Fatalf("Expected a delete event for %s\n", key)
+		}
+	}
+}